@@ -0,0 +1,19 @@
+module github.com/pakuula/go-rusty/cmd
+
+go 1.25.0
+
+require (
+	github.com/pakuula/go-rusty v0.0.0-00010101000000-000000000000
+	github.com/pmezard/go-difflib v1.0.0
+	github.com/stretchr/testify v1.8.4
+	golang.org/x/tools v0.45.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	golang.org/x/mod v0.36.0 // indirect
+	golang.org/x/sync v0.20.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/pakuula/go-rusty => ../
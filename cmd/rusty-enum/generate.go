@@ -0,0 +1,617 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/packages"
+)
+
+const (
+	resultPath = "github.com/pakuula/go-rusty/result"
+	optionPath = "github.com/pakuula/go-rusty/option"
+	directive  = "//rusty:enum"
+)
+
+var (
+	ErrLoad      = errors.New("failed to load the package")
+	ErrNotFound  = errors.New("no //rusty:enum directives")
+	ErrDirective = errors.New("invalid //rusty:enum directive")
+	ErrVariant   = errors.New("invalid variant")
+)
+
+// Settings of the generator
+type Config struct {
+	// Directory of the package, the current one if empty
+	Dir string
+	// Name of the generated file in Dir, its old content is ignored
+	Output string
+	// Command line arguments recorded in the header
+	Args string
+}
+
+// A sum type declared by a directive
+type enum struct {
+	name     string
+	pos      token.Pos
+	marker   string // the user-declared marker interface, empty for the spec comment
+	variants []*variant
+}
+
+type variant struct {
+	obj  *types.TypeName
+	spec *ast.TypeSpec
+	// The fields of the struct, nil for other types
+	fields []*field
+	// The underlying type of other types
+	underlying string
+	// The element enum of the underlying slice, empty if the type is not a slice of an enum
+	elem string
+}
+
+type field struct {
+	name, param, typ string
+	tag              string
+	// The enum of the field of the type E or []E
+	enum  string
+	slice bool
+}
+
+// Loads the package and returns the formatted source of the enums
+func Generate(cfg Config) ([]byte, error) {
+	dir := cfg.Dir
+	if dir == "" {
+		dir = "."
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	// The previous output may be stale or refer to removed variants
+	overlay := map[string][]byte{}
+	if cfg.Output != "" {
+		output := filepath.Join(dir, cfg.Output)
+		if file, err := parser.ParseFile(token.NewFileSet(), output, nil, parser.PackageClauseOnly); err == nil {
+			overlay[output] = []byte("package " + file.Name.Name + "\n")
+		}
+	}
+	mode := packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo
+	pkgs, err := packages.Load(&packages.Config{Mode: mode, Dir: dir, Overlay: overlay}, ".")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrLoad, err)
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("%w: expected one package in %s, got %d", ErrLoad, dir, len(pkgs))
+	}
+	pkg := pkgs[0]
+	// The code using the enums does not type-check until they are generated
+	for _, e := range pkg.Errors {
+		if e.Kind == packages.ParseError || len(pkg.Syntax) == 0 {
+			return nil, fmt.Errorf("%w: %v", ErrLoad, e)
+		}
+	}
+
+	g := &generator{
+		Config:  cfg,
+		fset:    pkg.Fset,
+		pkg:     pkg.Types,
+		info:    pkg.TypesInfo,
+		specs:   map[*types.TypeName]*ast.TypeSpec{},
+		enums:   map[string]bool{},
+		imports: map[string]string{},
+	}
+	enums, err := g.parseFiles(pkg.Syntax)
+	if err != nil {
+		return nil, err
+	}
+	if len(enums) == 0 {
+		return nil, fmt.Errorf("%s: %w", dir, ErrNotFound)
+	}
+	for _, e := range enums {
+		g.enums[e.name] = true
+	}
+	for _, e := range enums {
+		for _, v := range e.variants {
+			g.describe(v)
+		}
+	}
+	for _, e := range enums {
+		g.enum(e)
+	}
+	return g.source()
+}
+
+type generator struct {
+	Config
+	fset    *token.FileSet
+	pkg     *types.Package
+	info    *types.Info
+	specs   map[*types.TypeName]*ast.TypeSpec
+	enums   map[string]bool   // the names of the generated enums
+	imports map[string]string // import path -> package name
+	body    bytes.Buffer
+}
+
+// Finds the directives and the variants of the enums
+func (self *generator) parseFiles(files []*ast.File) ([]*enum, error) {
+	// The directives and the declarations they document
+	type found struct {
+		comment *ast.Comment
+		spec    *ast.TypeSpec
+	}
+	var directives []found
+	for _, file := range files {
+		if ast.IsGenerated(file) {
+			continue
+		}
+		docs := map[*ast.CommentGroup]*ast.TypeSpec{}
+		for _, decl := range file.Decls {
+			if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.TYPE {
+				for _, spec := range gen.Specs {
+					spec := spec.(*ast.TypeSpec)
+					if obj, ok := self.info.Defs[spec.Name].(*types.TypeName); ok {
+						self.specs[obj] = spec
+					}
+					if spec.Doc != nil {
+						docs[spec.Doc] = spec
+					} else if gen.Doc != nil && len(gen.Specs) == 1 {
+						docs[gen.Doc] = spec
+					}
+				}
+			}
+		}
+		for _, group := range file.Comments {
+			for _, c := range group.List {
+				if _, ok := parseDirective(c.Text); ok {
+					directives = append(directives, found{c, docs[group]})
+				}
+			}
+		}
+	}
+
+	var enums []*enum
+	owner := map[*types.TypeName]string{}
+	for _, d := range directives {
+		args, _ := parseDirective(d.comment.Text)
+		e, err := self.parse(d.comment.Pos(), args, d.spec)
+		if err != nil {
+			return nil, err
+		}
+		if e == nil {
+			continue
+		}
+		for _, v := range e.variants {
+			if other, ok := owner[v.obj]; ok {
+				return nil, self.errorf(d.comment.Pos(), ErrVariant, "%s is a variant of %s and %s", v.obj.Name(), other, e.name)
+			}
+			owner[v.obj] = e.name
+		}
+		enums = append(enums, e)
+	}
+	sort.Slice(enums, func(i, j int) bool { return self.less(enums[i].pos, enums[j].pos) })
+	return enums, nil
+}
+
+// Returns the arguments of the directive
+func parseDirective(text string) ([]string, bool) {
+	rest, ok := strings.CutPrefix(text, directive)
+	if !ok || rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return nil, false
+	}
+	return strings.Fields(rest), true
+}
+
+// Returns the enum of the directive, nil for the bare directive on an interface
+func (self *generator) parse(pos token.Pos, args []string, spec *ast.TypeSpec) (*enum, error) {
+	var marker *types.TypeName
+	if spec != nil {
+		if obj, ok := self.info.Defs[spec.Name].(*types.TypeName); ok && types.IsInterface(obj.Type()) {
+			marker = obj
+		}
+	}
+	switch {
+	case marker != nil && len(args) == 0:
+		// Only checked by rustyvet
+		return nil, nil
+	case marker != nil && len(args) > 1:
+		return nil, self.errorf(pos, ErrDirective, "the variants of %s implement it, expected only the name of the enum", marker.Name())
+	case marker == nil && len(args) < 2:
+		return nil, self.errorf(pos, ErrDirective, "expected the name of the enum and the variants")
+	}
+
+	e := &enum{name: args[0], pos: pos}
+	if !token.IsIdentifier(e.name) {
+		return nil, self.errorf(pos, ErrDirective, "%q is not an identifier", e.name)
+	}
+	for _, name := range []string{e.name, "Match" + e.name, "Unmarshal" + e.name + "Json"} {
+		if self.pkg.Scope().Lookup(name) != nil {
+			return nil, self.errorf(pos, ErrDirective, "%s is already declared", name)
+		}
+	}
+
+	var objs []*types.TypeName
+	if marker != nil {
+		e.marker = marker.Name()
+		iface := marker.Type().Underlying().(*types.Interface)
+		for obj, spec := range self.specs {
+			if obj != marker && spec.TypeParams == nil && !types.IsInterface(obj.Type()) && types.Implements(obj.Type(), iface) {
+				objs = append(objs, obj)
+			}
+		}
+		sort.Slice(objs, func(i, j int) bool { return self.less(objs[i].Pos(), objs[j].Pos()) })
+		if len(objs) == 0 {
+			return nil, self.errorf(pos, ErrVariant, "no types implement %s", marker.Name())
+		}
+	} else {
+		for _, name := range args[1:] {
+			obj, ok := self.pkg.Scope().Lookup(name).(*types.TypeName)
+			if !ok || types.IsInterface(obj.Type()) {
+				return nil, self.errorf(pos, ErrVariant, "%s is not a non-interface type of the package", name)
+			}
+			objs = append(objs, obj)
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, obj := range objs {
+		spec, ok := self.specs[obj]
+		if !ok || obj.IsAlias() || spec.TypeParams != nil {
+			return nil, self.errorf(pos, ErrVariant, "%s is not a non-generic defined type", obj.Name())
+		}
+		if seen[obj.Name()] {
+			return nil, self.errorf(pos, ErrVariant, "%s is listed twice", obj.Name())
+		}
+		seen[obj.Name()] = true
+		if self.pkg.Scope().Lookup(e.name+obj.Name()) != nil {
+			return nil, self.errorf(pos, ErrDirective, "%s is already declared", e.name+obj.Name())
+		}
+		for _, name := range []string{"MarshalJSON", "UnmarshalJSON"} {
+			if method(types.NewPointer(obj.Type()), name) {
+				return nil, self.errorf(pos, ErrVariant, "%s declares %s, the enum encodes its variants", obj.Name(), name)
+			}
+		}
+		if method(types.NewPointer(obj.Type()), "String") && !method(obj.Type(), "String") {
+			return nil, self.errorf(pos, ErrVariant, "%s declares String with a pointer receiver", obj.Name())
+		}
+		e.variants = append(e.variants, &variant{obj: obj, spec: spec})
+	}
+	return e, nil
+}
+
+// Reports if the type has the method
+func method(t types.Type, name string) bool {
+	obj, _, _ := types.LookupFieldOrMethod(t, false, nil, name)
+	_, ok := obj.(*types.Func)
+	return ok
+}
+
+func (self *generator) less(a, b token.Pos) bool {
+	pa, pb := self.fset.Position(a), self.fset.Position(b)
+	if pa.Filename != pb.Filename {
+		return pa.Filename < pb.Filename
+	}
+	return pa.Offset < pb.Offset
+}
+
+func (self *generator) errorf(pos token.Pos, kind error, format string, args ...any) error {
+	return fmt.Errorf("%s: %w: %s", self.fset.Position(pos), kind, fmt.Sprintf(format, args...))
+}
+
+// Collects the fields of the variant and their types
+func (self *generator) describe(v *variant) {
+	st, ok := v.spec.Type.(*ast.StructType)
+	if !ok {
+		v.underlying = self.typeString(v.spec.Type)
+		v.elem = self.enumSlice(v.spec.Type)
+		return
+	}
+	v.fields = []*field{}
+	taken := map[string]bool{}
+	for _, f := range st.Fields.List {
+		names := make([]string, len(f.Names))
+		for i, name := range f.Names {
+			names[i] = name.Name
+		}
+		if len(names) == 0 {
+			names = []string{embeddedName(f.Type)}
+		}
+		for _, name := range names {
+			fd := &field{name: name, typ: self.typeString(f.Type)}
+			fd.param = paramName(name)
+			for taken[fd.param] {
+				fd.param += "_"
+			}
+			taken[fd.param] = true
+			if f.Tag != nil {
+				fd.tag = f.Tag.Value
+			}
+			if ast.IsExported(name) {
+				if id, ok := f.Type.(*ast.Ident); ok && self.enums[id.Name] {
+					fd.enum = id.Name
+				} else if elem := self.enumSlice(f.Type); elem != "" {
+					fd.enum, fd.slice = elem, true
+				}
+			}
+			v.fields = append(v.fields, fd)
+		}
+	}
+}
+
+// Returns the name of the embedded field of the type
+func embeddedName(t ast.Expr) string {
+	for {
+		switch e := t.(type) {
+		case *ast.StarExpr:
+			t = e.X
+		case *ast.SelectorExpr:
+			t = e.Sel
+		case *ast.IndexExpr:
+			t = e.X
+		case *ast.IndexListExpr:
+			t = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return "_"
+		}
+	}
+}
+
+// Returns the enum of the type []E
+func (self *generator) enumSlice(t ast.Expr) string {
+	if arr, ok := t.(*ast.ArrayType); ok && arr.Len == nil {
+		if id, ok := arr.Elt.(*ast.Ident); ok && self.enums[id.Name] {
+			return id.Name
+		}
+	}
+	return ""
+}
+
+// Returns the name of the package in the generated file
+func (self *generator) use(pkg *types.Package) string {
+	if pkg == self.pkg {
+		return ""
+	}
+	if name, ok := self.imports[pkg.Path()]; ok {
+		return name
+	}
+	name := pkg.Name()
+	taken := map[string]bool{}
+	for _, other := range self.imports {
+		taken[other] = true
+	}
+	for i := 2; taken[name]; i++ {
+		name = fmt.Sprintf("%s%d", pkg.Name(), i)
+	}
+	self.imports[pkg.Path()] = name
+	return name
+}
+
+func (self *generator) lib(importPath string) string {
+	return self.use(types.NewPackage(importPath, path.Base(importPath)))
+}
+
+// Returns the type expression in the generated file.
+// The types referring to the enums are invalid until they are generated,
+// so the expression is taken from the source.
+func (self *generator) typeString(expr ast.Expr) string {
+	if t := self.info.TypeOf(expr); t != nil && !strings.Contains(t.String(), "invalid type") {
+		return types.TypeString(t, self.use)
+	}
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			if pkgName, ok := self.info.Uses[id].(*types.PkgName); ok {
+				self.use(pkgName.Imported())
+			}
+		}
+		return true
+	})
+	return types.ExprString(expr)
+}
+
+func (self *generator) enum(e *enum) {
+	name := e.name
+	marker := "is" + name + "()"
+	if e.marker != "" {
+		marker = e.marker
+	}
+	option, fmt_ := self.lib(optionPath), self.lib("fmt")
+	names := make([]string, len(e.variants))
+	for i, v := range e.variants {
+		names[i] = v.obj.Name()
+	}
+
+	// The interface
+	fmt.Fprintf(&self.body, "// %s is one of %s.\n//\n%s\n", name, strings.Join(names, ", "), directive)
+	fmt.Fprintf(&self.body, "type %s interface {\n%s\n%s.Stringer\n", name, marker, fmt_)
+	for _, v := range names {
+		fmt.Fprintf(&self.body, "Is%s() bool\nAs%s() %s.Option[%s]\n", v, v, option, v)
+	}
+	self.body.WriteString("}\n\n")
+
+	// The constructors
+	for _, v := range e.variants {
+		fmt.Fprintf(&self.body, "// %s%s returns %s as %s.\n", name, v.obj.Name(), v.obj.Name(), name)
+		if v.fields == nil {
+			fmt.Fprintf(&self.body, "func %s%s(v %s) %s {\nreturn %s(v)\n}\n\n", name, v.obj.Name(), v.underlying, name, v.obj.Name())
+			continue
+		}
+		// The unexported fields are left zero
+		var params, values []string
+		for _, f := range v.fields {
+			if ast.IsExported(f.name) {
+				params = append(params, f.param+" "+f.typ)
+				values = append(values, f.name+": "+f.param)
+			}
+		}
+		fmt.Fprintf(&self.body, "func %s%s(%s) %s {\nreturn %s{%s}\n}\n\n",
+			name, v.obj.Name(), strings.Join(params, ", "), name, v.obj.Name(), strings.Join(values, ", "))
+	}
+
+	// The methods of the variants
+	for _, v := range e.variants {
+		vn := v.obj.Name()
+		if e.marker == "" {
+			fmt.Fprintf(&self.body, "func (%s) is%s() {}\n\n", vn, name)
+		}
+		for _, w := range names {
+			if w == vn {
+				fmt.Fprintf(&self.body, "// Is%s is true.\n", w)
+				fmt.Fprintf(&self.body, "func (%s) Is%s() bool { return true }\n\n", vn, w)
+				fmt.Fprintf(&self.body, "// As%s returns the value.\n", w)
+				fmt.Fprintf(&self.body, "func (self %s) As%s() %s.Option[%s] { return %s.Some(self) }\n\n", vn, w, option, w, option)
+			} else {
+				fmt.Fprintf(&self.body, "// Is%s is false.\n", w)
+				fmt.Fprintf(&self.body, "func (%s) Is%s() bool { return false }\n\n", vn, w)
+				fmt.Fprintf(&self.body, "// As%s returns None.\n", w)
+				fmt.Fprintf(&self.body, "func (%s) As%s() %s.Option[%s] { return %s.None[%s]() }\n\n", vn, w, option, w, option, w)
+			}
+		}
+		if !method(v.obj.Type(), "String") {
+			fmt.Fprintf(&self.body, "// String returns %s(value).\n", vn)
+			fmt.Fprintf(&self.body, "func (self %s) String() string {\ntype plain %s\nreturn %s.Sprintf(\"%s(%%+v)\", plain(self))\n}\n\n",
+				vn, vn, fmt_, vn)
+		}
+		self.json(v)
+	}
+
+	// Match
+	callbacks := make([]string, len(names))
+	for i, v := range names {
+		callbacks[i] = fmt.Sprintf("on%s func(%s) U", v, v)
+	}
+	// The pointers to the variants implement the enum too, Go can't forbid them
+	fmt.Fprintf(&self.body, "// Match%s calls the function for the variant of the value.\n", name)
+	fmt.Fprintf(&self.body, "// A pointer to a variant is matched as the variant.\n//\n")
+	fmt.Fprintf(&self.body, "// Panics if the value or the pointer is nil, or if the value is not a variant.\n")
+	fmt.Fprintf(&self.body, "func Match%s[U any](value %s, %s) U {\nswitch v := value.(type) {\n", name, name, strings.Join(callbacks, ", "))
+	for _, v := range names {
+		fmt.Fprintf(&self.body, "case %s:\nreturn on%s(v)\n", v, v)
+		fmt.Fprintf(&self.body, "case *%s:\nif v == nil {\npanic(\"Match%s: nil *%s\")\n}\nreturn on%s(*v)\n", v, name, v, v)
+	}
+	fmt.Fprintf(&self.body, "case nil:\npanic(\"Match%s: nil %s\")\n}\n", name, name)
+	fmt.Fprintf(&self.body, "panic(%s.Sprintf(\"Match%s: %%T is not a variant of %s\", value))\n}\n\n", fmt_, name, name)
+
+	// Unmarshal
+	result, json := self.lib(resultPath), self.lib("encoding/json")
+	fmt.Fprintf(&self.body, "// Unmarshal%sJson decodes %s from {\"Variant\": value}.\n", name, name)
+	fmt.Fprintf(&self.body, "func Unmarshal%sJson(data []byte) %s.Result[%s] {\n", name, result, name)
+	fmt.Fprintf(&self.body, "var tagged map[string]%s.RawMessage\n", json)
+	fmt.Fprintf(&self.body, "if err := %s.Unmarshal(data, &tagged); err != nil {\nreturn %s.Err[%s](err)\n}\n", json, result, name)
+	fmt.Fprintf(&self.body, "if len(tagged) != 1 {\nreturn %s.Err[%s](%s.Errorf(\"%s: expected one variant, got %%d\", len(tagged)))\n}\n", result, name, fmt_, name)
+	fmt.Fprintf(&self.body, "var tag string\nfor tag = range tagged {\n}\nswitch tag {\n")
+	for _, v := range names {
+		fmt.Fprintf(&self.body, "case %q:\nvar v %s\nerr := %s.Unmarshal(data, &v)\nreturn %s.Wrap[%s](v, err)\n", v, v, json, result, name)
+	}
+	fmt.Fprintf(&self.body, "}\nreturn %s.Err[%s](%s.Errorf(\"%s: unknown variant %%q\", tag))\n}\n\n", result, name, fmt_, name)
+}
+
+// Writes MarshalJSON and UnmarshalJSON of the variant
+func (self *generator) json(v *variant) {
+	vn := v.obj.Name()
+	json, fmt_ := self.lib("encoding/json"), self.lib("fmt")
+	fmt.Fprintf(&self.body, "// MarshalJSON encodes the value as {\"%s\": value}.\n", vn)
+	fmt.Fprintf(&self.body, "func (self %s) MarshalJSON() ([]byte, error) {\ntype plain %s\nreturn %s.Marshal(map[string]plain{%q: plain(self)})\n}\n\n",
+		vn, vn, json, vn)
+
+	fmt.Fprintf(&self.body, "// UnmarshalJSON decodes the value from {\"%s\": value}.\n", vn)
+	fmt.Fprintf(&self.body, "func (self *%s) UnmarshalJSON(data []byte) error {\n", vn)
+	fmt.Fprintf(&self.body, "var tagged map[string]%s.RawMessage\n", json)
+	fmt.Fprintf(&self.body, "if err := %s.Unmarshal(data, &tagged); err != nil {\nreturn err\n}\n", json)
+	fmt.Fprintf(&self.body, "value, ok := tagged[%q]\nif !ok || len(tagged) != 1 {\nreturn %s.Errorf(\"%s: expected {\\\"%s\\\": value}\")\n}\n", vn, fmt_, vn, vn)
+
+	var enumFields []*field
+	for _, f := range v.fields {
+		if f.enum != "" {
+			enumFields = append(enumFields, f)
+		}
+	}
+	switch {
+	case v.elem != "":
+		// The slice of an enum
+		fmt.Fprintf(&self.body, "var items []%s.RawMessage\nif err := %s.Unmarshal(value, &items); err != nil {\nreturn err\n}\n", json, json)
+		fmt.Fprintf(&self.body, "*self = nil\nfor _, item := range items {\n")
+		fmt.Fprintf(&self.body, "decoded, err := Unmarshal%sJson(item).UnwrapWithError()\nif err != nil {\nreturn err\n}\n*self = append(*self, decoded)\n}\nreturn nil\n}\n\n", v.elem)
+	case len(enumFields) > 0:
+		// The fields of the enum types shadow the fields of the struct
+		fmt.Fprintf(&self.body, "type plain %s\nvar fields struct {\n*plain\n", vn)
+		for _, f := range enumFields {
+			typ := json + ".RawMessage"
+			if f.slice {
+				typ = "[]" + typ
+			}
+			fmt.Fprintf(&self.body, "%s %s %s\n", f.name, typ, f.tag)
+		}
+		fmt.Fprintf(&self.body, "}\nfields.plain = (*plain)(self)\nif err := %s.Unmarshal(value, &fields); err != nil {\nreturn err\n}\n", json)
+		for _, f := range enumFields {
+			if f.slice {
+				fmt.Fprintf(&self.body, "self.%s = nil\nfor _, item := range fields.%s {\n", f.name, f.name)
+				fmt.Fprintf(&self.body, "decoded, err := Unmarshal%sJson(item).UnwrapWithError()\nif err != nil {\nreturn err\n}\nself.%s = append(self.%s, decoded)\n}\n", f.enum, f.name, f.name)
+				continue
+			}
+			fmt.Fprintf(&self.body, "if len(fields.%s) > 0 && string(fields.%s) != \"null\" {\n", f.name, f.name)
+			fmt.Fprintf(&self.body, "decoded, err := Unmarshal%sJson(fields.%s).UnwrapWithError()\nif err != nil {\nreturn err\n}\nself.%s = decoded\n}\n", f.enum, f.name, f.name)
+		}
+		self.body.WriteString("return nil\n}\n\n")
+	default:
+		fmt.Fprintf(&self.body, "type plain %s\nreturn %s.Unmarshal(value, (*plain)(self))\n}\n\n", vn, json)
+	}
+}
+
+func paramName(field string) string {
+	r, size := utf8.DecodeRuneInString(field)
+	name := string(unicode.ToLower(r)) + field[size:]
+	if token.IsKeyword(name) || name == "_" {
+		name += "_"
+	}
+	return name
+}
+
+func (self *generator) source() ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by rusty-enum %s; DO NOT EDIT.\n\n", self.Args)
+	fmt.Fprintf(&buf, "package %s\n\n", self.pkg.Name())
+
+	paths := make([]string, 0, len(self.imports))
+	for p := range self.imports {
+		paths = append(paths, p)
+	}
+	// Standard library first, then the rest
+	sort.Slice(paths, func(i, j int) bool {
+		iStd, jStd := !strings.Contains(paths[i], "."), !strings.Contains(paths[j], ".")
+		if iStd != jStd {
+			return iStd
+		}
+		return paths[i] < paths[j]
+	})
+	buf.WriteString("import (\n")
+	for i, p := range paths {
+		if i > 0 && strings.Contains(p, ".") && !strings.Contains(paths[i-1], ".") {
+			buf.WriteString("\n")
+		}
+		if name := self.imports[p]; name != path.Base(p) {
+			fmt.Fprintf(&buf, "%s %q\n", name, p)
+		} else {
+			fmt.Fprintf(&buf, "%q\n", p)
+		}
+	}
+	buf.WriteString(")\n\n")
+	buf.Write(self.body.Bytes())
+	return format.Source(buf.Bytes())
+}
@@ -0,0 +1,115 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pakuula/go-rusty/cmd/rusty-enum/testdata/shape"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+const golden = "testdata/shape/enum_gen.go"
+
+func TestGenerate(t *testing.T) {
+	src, err := Generate(Config{Dir: "testdata/shape", Output: "enum_gen.go", Args: "-C rusty-enum/testdata/shape"})
+	require.NoError(t, err)
+	if *update {
+		require.NoError(t, os.WriteFile(golden, src, 0o644))
+	}
+	expected, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(src))
+}
+
+func TestErrors(t *testing.T) {
+	for dir, expected := range map[string]error{
+		"testdata/bad/args":    ErrDirective,
+		"testdata/bad/iface":   ErrVariant,
+		"testdata/bad/marshal": ErrVariant,
+		"testdata/bad/none":    ErrNotFound,
+		"testdata/missing":     ErrLoad,
+	} {
+		_, err := Generate(Config{Dir: dir})
+		assert.ErrorIs(t, err, expected, dir)
+	}
+}
+
+func TestAccessors(t *testing.T) {
+	s := shape.ShapeCircle(2)
+	assert.True(t, s.IsCircle())
+	assert.False(t, s.IsRect())
+	assert.Equal(t, shape.Circle{Radius: 2}, s.AsCircle().Unwrap())
+	assert.True(t, s.AsDot().IsNone())
+
+	assert.Equal(t, "Circle({Radius:2})", s.String())
+	assert.Equal(t, "Rect", shape.ShapeRect(1, 2).String())
+	assert.Equal(t, "Dot({})", shape.ShapeDot().String())
+	assert.Equal(t, "Neg({X:Num(1)})", shape.ExprNeg(shape.ExprNum(1)).String())
+}
+
+// Satisfies Shape by embedding a variant
+type embedded struct{ shape.Circle }
+
+func TestMatch(t *testing.T) {
+	area := func(s shape.Shape) float64 {
+		return shape.MatchShape(s,
+			shape.Circle.Area,
+			func(r shape.Rect) float64 { return r.Width * r.Height },
+			func(shape.Dot) float64 { return 0 },
+		)
+	}
+	assert.Equal(t, 6.0, area(shape.ShapeRect(2, 3)))
+	assert.Equal(t, 0.0, area(shape.ShapeDot()))
+	assert.PanicsWithValue(t, "MatchShape: nil Shape", func() { area(nil) })
+
+	// Go can't keep the pointers out of the enum
+	assert.Equal(t, 6.0, area(&shape.Rect{Width: 2, Height: 3}))
+	assert.PanicsWithValue(t, "MatchShape: nil *Rect", func() { area((*shape.Rect)(nil)) })
+	assert.PanicsWithValue(t, "MatchShape: main.embedded is not a variant of Shape", func() { area(embedded{}) })
+
+	e := shape.ExprSum([]shape.Expr{shape.ExprNum(1), shape.ExprNeg(shape.ExprNum(3))})
+	assert.Equal(t, -2.0, shape.Eval(e))
+}
+
+func TestJson(t *testing.T) {
+	data, err := json.Marshal([]shape.Shape{shape.ShapeCircle(1), shape.ShapeDot()})
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"Circle": {"radius": 1}}, {"Dot": {}}]`, string(data))
+
+	s := shape.UnmarshalShapeJson([]byte(`{"Rect": {"Width": 2, "Height": 3}}`))
+	assert.Equal(t, shape.Rect{Width: 2, Height: 3}, s.Unwrap())
+	assert.ErrorContains(t, shape.UnmarshalShapeJson([]byte(`{"Square": {}}`)).Err(), `unknown variant "Square"`)
+	assert.ErrorContains(t, shape.UnmarshalShapeJson([]byte(`{}`)).Err(), "expected one variant, got 0")
+
+	// The recursive enum
+	e := shape.ExprSeq([]shape.Expr{
+		shape.ExprSum([]shape.Expr{shape.ExprNum(1), shape.ExprNeg(shape.ExprNum(2))}),
+		shape.ExprTimeout(time.Second, nil),
+		shape.ExprTimeout(time.Second, shape.ExprNum(3)),
+	})
+	data, err = json.Marshal(e)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"Seq": [
+		{"Sum": {"terms": [{"Num": 1}, {"Neg": {"X": {"Num": 2}}}]}},
+		{"Timeout": {"Duration": 1000000000}},
+		{"Timeout": {"Duration": 1000000000, "fallback": {"Num": 3}}}
+	]}`, string(data))
+	assert.Equal(t, e, shape.UnmarshalExprJson(data).Unwrap())
+
+	// The variant decodes itself from the tagged form
+	var sum shape.Sum
+	require.NoError(t, json.Unmarshal([]byte(`{"Sum": {"terms": [{"Num": 4}]}}`), &sum))
+	assert.Equal(t, shape.Sum{Terms: []shape.Expr{shape.Num(4)}}, sum)
+	assert.Error(t, json.Unmarshal([]byte(`{"terms": []}`), &sum))
+}
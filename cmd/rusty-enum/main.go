@@ -0,0 +1,68 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+// Command rusty-enum generates sum types, the analogue of Rust enums.
+//
+// The enum is declared by a spec comment listing its variants:
+//
+//	//rusty:enum Shape Circle Rect
+//
+// or by the directive on an interface with a marker method,
+// the variants are the types of the package implementing it:
+//
+//	//rusty:enum Shape
+//	type shape interface{ isShape() }
+//
+// The generated interface Shape is implemented by the variants and,
+// as Go can't forbid it, by the pointers to them.
+// The tool generates the constructors ShapeCircle and ShapeRect,
+// the methods IsCircle and AsCircle returning option.Option[Circle],
+// the exhaustive MatchShape taking one function per variant and matching *Circle as Circle,
+// the JSON encoding {"Circle": {...}} decoded by UnmarshalShapeJson,
+// and String.
+//
+// The type switches over the enum missing a variant are reported by rustyvet.
+//
+// Usage:
+//
+//	rusty-enum [-C dir] [-o enum_gen.go]
+//
+// The tool is meant to be run by go generate:
+//
+//	//go:generate go run github.com/pakuula/go-rusty/cmd/rusty-enum
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	var cfg Config
+	flag.StringVar(&cfg.Dir, "C", "", "directory of the package (default the current one)")
+	flag.StringVar(&cfg.Output, "o", "enum_gen.go", "output file in the directory of the package")
+	flag.Parse()
+
+	if flag.NArg() != 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	cfg.Args = strings.Join(os.Args[1:], " ")
+
+	src, err := Generate(cfg)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	if err := os.WriteFile(filepath.Join(cfg.Dir, cfg.Output), src, 0o644); err != nil {
+		fatalf("%v", err)
+	}
+}
+
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "rusty-enum: "+format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,9 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package args
+
+//rusty:enum Shape
+
+type Circle struct{}
@@ -0,0 +1,693 @@
+// Code generated by rusty-enum -C rusty-enum/testdata/shape; DO NOT EDIT.
+
+package shape
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pakuula/go-rusty/option"
+	"github.com/pakuula/go-rusty/result"
+)
+
+// Shape is one of Circle, Rect, Dot.
+//
+//rusty:enum
+type Shape interface {
+	isShape()
+	fmt.Stringer
+	IsCircle() bool
+	AsCircle() option.Option[Circle]
+	IsRect() bool
+	AsRect() option.Option[Rect]
+	IsDot() bool
+	AsDot() option.Option[Dot]
+}
+
+// ShapeCircle returns Circle as Shape.
+func ShapeCircle(radius float64) Shape {
+	return Circle{Radius: radius}
+}
+
+// ShapeRect returns Rect as Shape.
+func ShapeRect(width float64, height float64) Shape {
+	return Rect{Width: width, Height: height}
+}
+
+// ShapeDot returns Dot as Shape.
+func ShapeDot() Shape {
+	return Dot{}
+}
+
+func (Circle) isShape() {}
+
+// IsCircle is true.
+func (Circle) IsCircle() bool { return true }
+
+// AsCircle returns the value.
+func (self Circle) AsCircle() option.Option[Circle] { return option.Some(self) }
+
+// IsRect is false.
+func (Circle) IsRect() bool { return false }
+
+// AsRect returns None.
+func (Circle) AsRect() option.Option[Rect] { return option.None[Rect]() }
+
+// IsDot is false.
+func (Circle) IsDot() bool { return false }
+
+// AsDot returns None.
+func (Circle) AsDot() option.Option[Dot] { return option.None[Dot]() }
+
+// String returns Circle(value).
+func (self Circle) String() string {
+	type plain Circle
+	return fmt.Sprintf("Circle(%+v)", plain(self))
+}
+
+// MarshalJSON encodes the value as {"Circle": value}.
+func (self Circle) MarshalJSON() ([]byte, error) {
+	type plain Circle
+	return json.Marshal(map[string]plain{"Circle": plain(self)})
+}
+
+// UnmarshalJSON decodes the value from {"Circle": value}.
+func (self *Circle) UnmarshalJSON(data []byte) error {
+	var tagged map[string]json.RawMessage
+	if err := json.Unmarshal(data, &tagged); err != nil {
+		return err
+	}
+	value, ok := tagged["Circle"]
+	if !ok || len(tagged) != 1 {
+		return fmt.Errorf("Circle: expected {\"Circle\": value}")
+	}
+	type plain Circle
+	return json.Unmarshal(value, (*plain)(self))
+}
+
+func (Rect) isShape() {}
+
+// IsCircle is false.
+func (Rect) IsCircle() bool { return false }
+
+// AsCircle returns None.
+func (Rect) AsCircle() option.Option[Circle] { return option.None[Circle]() }
+
+// IsRect is true.
+func (Rect) IsRect() bool { return true }
+
+// AsRect returns the value.
+func (self Rect) AsRect() option.Option[Rect] { return option.Some(self) }
+
+// IsDot is false.
+func (Rect) IsDot() bool { return false }
+
+// AsDot returns None.
+func (Rect) AsDot() option.Option[Dot] { return option.None[Dot]() }
+
+// MarshalJSON encodes the value as {"Rect": value}.
+func (self Rect) MarshalJSON() ([]byte, error) {
+	type plain Rect
+	return json.Marshal(map[string]plain{"Rect": plain(self)})
+}
+
+// UnmarshalJSON decodes the value from {"Rect": value}.
+func (self *Rect) UnmarshalJSON(data []byte) error {
+	var tagged map[string]json.RawMessage
+	if err := json.Unmarshal(data, &tagged); err != nil {
+		return err
+	}
+	value, ok := tagged["Rect"]
+	if !ok || len(tagged) != 1 {
+		return fmt.Errorf("Rect: expected {\"Rect\": value}")
+	}
+	type plain Rect
+	return json.Unmarshal(value, (*plain)(self))
+}
+
+func (Dot) isShape() {}
+
+// IsCircle is false.
+func (Dot) IsCircle() bool { return false }
+
+// AsCircle returns None.
+func (Dot) AsCircle() option.Option[Circle] { return option.None[Circle]() }
+
+// IsRect is false.
+func (Dot) IsRect() bool { return false }
+
+// AsRect returns None.
+func (Dot) AsRect() option.Option[Rect] { return option.None[Rect]() }
+
+// IsDot is true.
+func (Dot) IsDot() bool { return true }
+
+// AsDot returns the value.
+func (self Dot) AsDot() option.Option[Dot] { return option.Some(self) }
+
+// String returns Dot(value).
+func (self Dot) String() string {
+	type plain Dot
+	return fmt.Sprintf("Dot(%+v)", plain(self))
+}
+
+// MarshalJSON encodes the value as {"Dot": value}.
+func (self Dot) MarshalJSON() ([]byte, error) {
+	type plain Dot
+	return json.Marshal(map[string]plain{"Dot": plain(self)})
+}
+
+// UnmarshalJSON decodes the value from {"Dot": value}.
+func (self *Dot) UnmarshalJSON(data []byte) error {
+	var tagged map[string]json.RawMessage
+	if err := json.Unmarshal(data, &tagged); err != nil {
+		return err
+	}
+	value, ok := tagged["Dot"]
+	if !ok || len(tagged) != 1 {
+		return fmt.Errorf("Dot: expected {\"Dot\": value}")
+	}
+	type plain Dot
+	return json.Unmarshal(value, (*plain)(self))
+}
+
+// MatchShape calls the function for the variant of the value.
+// A pointer to a variant is matched as the variant.
+//
+// Panics if the value or the pointer is nil, or if the value is not a variant.
+func MatchShape[U any](value Shape, onCircle func(Circle) U, onRect func(Rect) U, onDot func(Dot) U) U {
+	switch v := value.(type) {
+	case Circle:
+		return onCircle(v)
+	case *Circle:
+		if v == nil {
+			panic("MatchShape: nil *Circle")
+		}
+		return onCircle(*v)
+	case Rect:
+		return onRect(v)
+	case *Rect:
+		if v == nil {
+			panic("MatchShape: nil *Rect")
+		}
+		return onRect(*v)
+	case Dot:
+		return onDot(v)
+	case *Dot:
+		if v == nil {
+			panic("MatchShape: nil *Dot")
+		}
+		return onDot(*v)
+	case nil:
+		panic("MatchShape: nil Shape")
+	}
+	panic(fmt.Sprintf("MatchShape: %T is not a variant of Shape", value))
+}
+
+// UnmarshalShapeJson decodes Shape from {"Variant": value}.
+func UnmarshalShapeJson(data []byte) result.Result[Shape] {
+	var tagged map[string]json.RawMessage
+	if err := json.Unmarshal(data, &tagged); err != nil {
+		return result.Err[Shape](err)
+	}
+	if len(tagged) != 1 {
+		return result.Err[Shape](fmt.Errorf("Shape: expected one variant, got %d", len(tagged)))
+	}
+	var tag string
+	for tag = range tagged {
+	}
+	switch tag {
+	case "Circle":
+		var v Circle
+		err := json.Unmarshal(data, &v)
+		return result.Wrap[Shape](v, err)
+	case "Rect":
+		var v Rect
+		err := json.Unmarshal(data, &v)
+		return result.Wrap[Shape](v, err)
+	case "Dot":
+		var v Dot
+		err := json.Unmarshal(data, &v)
+		return result.Wrap[Shape](v, err)
+	}
+	return result.Err[Shape](fmt.Errorf("Shape: unknown variant %q", tag))
+}
+
+// Expr is one of Num, Neg, Sum, Timeout, Seq.
+//
+//rusty:enum
+type Expr interface {
+	expr
+	fmt.Stringer
+	IsNum() bool
+	AsNum() option.Option[Num]
+	IsNeg() bool
+	AsNeg() option.Option[Neg]
+	IsSum() bool
+	AsSum() option.Option[Sum]
+	IsTimeout() bool
+	AsTimeout() option.Option[Timeout]
+	IsSeq() bool
+	AsSeq() option.Option[Seq]
+}
+
+// ExprNum returns Num as Expr.
+func ExprNum(v float64) Expr {
+	return Num(v)
+}
+
+// ExprNeg returns Neg as Expr.
+func ExprNeg(x Expr) Expr {
+	return Neg{X: x}
+}
+
+// ExprSum returns Sum as Expr.
+func ExprSum(terms []Expr) Expr {
+	return Sum{Terms: terms}
+}
+
+// ExprTimeout returns Timeout as Expr.
+func ExprTimeout(duration time.Duration, fallback Expr) Expr {
+	return Timeout{Duration: duration, Fallback: fallback}
+}
+
+// ExprSeq returns Seq as Expr.
+func ExprSeq(v []Expr) Expr {
+	return Seq(v)
+}
+
+// IsNum is true.
+func (Num) IsNum() bool { return true }
+
+// AsNum returns the value.
+func (self Num) AsNum() option.Option[Num] { return option.Some(self) }
+
+// IsNeg is false.
+func (Num) IsNeg() bool { return false }
+
+// AsNeg returns None.
+func (Num) AsNeg() option.Option[Neg] { return option.None[Neg]() }
+
+// IsSum is false.
+func (Num) IsSum() bool { return false }
+
+// AsSum returns None.
+func (Num) AsSum() option.Option[Sum] { return option.None[Sum]() }
+
+// IsTimeout is false.
+func (Num) IsTimeout() bool { return false }
+
+// AsTimeout returns None.
+func (Num) AsTimeout() option.Option[Timeout] { return option.None[Timeout]() }
+
+// IsSeq is false.
+func (Num) IsSeq() bool { return false }
+
+// AsSeq returns None.
+func (Num) AsSeq() option.Option[Seq] { return option.None[Seq]() }
+
+// String returns Num(value).
+func (self Num) String() string {
+	type plain Num
+	return fmt.Sprintf("Num(%+v)", plain(self))
+}
+
+// MarshalJSON encodes the value as {"Num": value}.
+func (self Num) MarshalJSON() ([]byte, error) {
+	type plain Num
+	return json.Marshal(map[string]plain{"Num": plain(self)})
+}
+
+// UnmarshalJSON decodes the value from {"Num": value}.
+func (self *Num) UnmarshalJSON(data []byte) error {
+	var tagged map[string]json.RawMessage
+	if err := json.Unmarshal(data, &tagged); err != nil {
+		return err
+	}
+	value, ok := tagged["Num"]
+	if !ok || len(tagged) != 1 {
+		return fmt.Errorf("Num: expected {\"Num\": value}")
+	}
+	type plain Num
+	return json.Unmarshal(value, (*plain)(self))
+}
+
+// IsNum is false.
+func (Neg) IsNum() bool { return false }
+
+// AsNum returns None.
+func (Neg) AsNum() option.Option[Num] { return option.None[Num]() }
+
+// IsNeg is true.
+func (Neg) IsNeg() bool { return true }
+
+// AsNeg returns the value.
+func (self Neg) AsNeg() option.Option[Neg] { return option.Some(self) }
+
+// IsSum is false.
+func (Neg) IsSum() bool { return false }
+
+// AsSum returns None.
+func (Neg) AsSum() option.Option[Sum] { return option.None[Sum]() }
+
+// IsTimeout is false.
+func (Neg) IsTimeout() bool { return false }
+
+// AsTimeout returns None.
+func (Neg) AsTimeout() option.Option[Timeout] { return option.None[Timeout]() }
+
+// IsSeq is false.
+func (Neg) IsSeq() bool { return false }
+
+// AsSeq returns None.
+func (Neg) AsSeq() option.Option[Seq] { return option.None[Seq]() }
+
+// String returns Neg(value).
+func (self Neg) String() string {
+	type plain Neg
+	return fmt.Sprintf("Neg(%+v)", plain(self))
+}
+
+// MarshalJSON encodes the value as {"Neg": value}.
+func (self Neg) MarshalJSON() ([]byte, error) {
+	type plain Neg
+	return json.Marshal(map[string]plain{"Neg": plain(self)})
+}
+
+// UnmarshalJSON decodes the value from {"Neg": value}.
+func (self *Neg) UnmarshalJSON(data []byte) error {
+	var tagged map[string]json.RawMessage
+	if err := json.Unmarshal(data, &tagged); err != nil {
+		return err
+	}
+	value, ok := tagged["Neg"]
+	if !ok || len(tagged) != 1 {
+		return fmt.Errorf("Neg: expected {\"Neg\": value}")
+	}
+	type plain Neg
+	var fields struct {
+		*plain
+		X json.RawMessage
+	}
+	fields.plain = (*plain)(self)
+	if err := json.Unmarshal(value, &fields); err != nil {
+		return err
+	}
+	if len(fields.X) > 0 && string(fields.X) != "null" {
+		decoded, err := UnmarshalExprJson(fields.X).UnwrapWithError()
+		if err != nil {
+			return err
+		}
+		self.X = decoded
+	}
+	return nil
+}
+
+// IsNum is false.
+func (Sum) IsNum() bool { return false }
+
+// AsNum returns None.
+func (Sum) AsNum() option.Option[Num] { return option.None[Num]() }
+
+// IsNeg is false.
+func (Sum) IsNeg() bool { return false }
+
+// AsNeg returns None.
+func (Sum) AsNeg() option.Option[Neg] { return option.None[Neg]() }
+
+// IsSum is true.
+func (Sum) IsSum() bool { return true }
+
+// AsSum returns the value.
+func (self Sum) AsSum() option.Option[Sum] { return option.Some(self) }
+
+// IsTimeout is false.
+func (Sum) IsTimeout() bool { return false }
+
+// AsTimeout returns None.
+func (Sum) AsTimeout() option.Option[Timeout] { return option.None[Timeout]() }
+
+// IsSeq is false.
+func (Sum) IsSeq() bool { return false }
+
+// AsSeq returns None.
+func (Sum) AsSeq() option.Option[Seq] { return option.None[Seq]() }
+
+// String returns Sum(value).
+func (self Sum) String() string {
+	type plain Sum
+	return fmt.Sprintf("Sum(%+v)", plain(self))
+}
+
+// MarshalJSON encodes the value as {"Sum": value}.
+func (self Sum) MarshalJSON() ([]byte, error) {
+	type plain Sum
+	return json.Marshal(map[string]plain{"Sum": plain(self)})
+}
+
+// UnmarshalJSON decodes the value from {"Sum": value}.
+func (self *Sum) UnmarshalJSON(data []byte) error {
+	var tagged map[string]json.RawMessage
+	if err := json.Unmarshal(data, &tagged); err != nil {
+		return err
+	}
+	value, ok := tagged["Sum"]
+	if !ok || len(tagged) != 1 {
+		return fmt.Errorf("Sum: expected {\"Sum\": value}")
+	}
+	type plain Sum
+	var fields struct {
+		*plain
+		Terms []json.RawMessage `json:"terms"`
+	}
+	fields.plain = (*plain)(self)
+	if err := json.Unmarshal(value, &fields); err != nil {
+		return err
+	}
+	self.Terms = nil
+	for _, item := range fields.Terms {
+		decoded, err := UnmarshalExprJson(item).UnwrapWithError()
+		if err != nil {
+			return err
+		}
+		self.Terms = append(self.Terms, decoded)
+	}
+	return nil
+}
+
+// IsNum is false.
+func (Timeout) IsNum() bool { return false }
+
+// AsNum returns None.
+func (Timeout) AsNum() option.Option[Num] { return option.None[Num]() }
+
+// IsNeg is false.
+func (Timeout) IsNeg() bool { return false }
+
+// AsNeg returns None.
+func (Timeout) AsNeg() option.Option[Neg] { return option.None[Neg]() }
+
+// IsSum is false.
+func (Timeout) IsSum() bool { return false }
+
+// AsSum returns None.
+func (Timeout) AsSum() option.Option[Sum] { return option.None[Sum]() }
+
+// IsTimeout is true.
+func (Timeout) IsTimeout() bool { return true }
+
+// AsTimeout returns the value.
+func (self Timeout) AsTimeout() option.Option[Timeout] { return option.Some(self) }
+
+// IsSeq is false.
+func (Timeout) IsSeq() bool { return false }
+
+// AsSeq returns None.
+func (Timeout) AsSeq() option.Option[Seq] { return option.None[Seq]() }
+
+// MarshalJSON encodes the value as {"Timeout": value}.
+func (self Timeout) MarshalJSON() ([]byte, error) {
+	type plain Timeout
+	return json.Marshal(map[string]plain{"Timeout": plain(self)})
+}
+
+// UnmarshalJSON decodes the value from {"Timeout": value}.
+func (self *Timeout) UnmarshalJSON(data []byte) error {
+	var tagged map[string]json.RawMessage
+	if err := json.Unmarshal(data, &tagged); err != nil {
+		return err
+	}
+	value, ok := tagged["Timeout"]
+	if !ok || len(tagged) != 1 {
+		return fmt.Errorf("Timeout: expected {\"Timeout\": value}")
+	}
+	type plain Timeout
+	var fields struct {
+		*plain
+		Fallback json.RawMessage `json:"fallback,omitempty"`
+	}
+	fields.plain = (*plain)(self)
+	if err := json.Unmarshal(value, &fields); err != nil {
+		return err
+	}
+	if len(fields.Fallback) > 0 && string(fields.Fallback) != "null" {
+		decoded, err := UnmarshalExprJson(fields.Fallback).UnwrapWithError()
+		if err != nil {
+			return err
+		}
+		self.Fallback = decoded
+	}
+	return nil
+}
+
+// IsNum is false.
+func (Seq) IsNum() bool { return false }
+
+// AsNum returns None.
+func (Seq) AsNum() option.Option[Num] { return option.None[Num]() }
+
+// IsNeg is false.
+func (Seq) IsNeg() bool { return false }
+
+// AsNeg returns None.
+func (Seq) AsNeg() option.Option[Neg] { return option.None[Neg]() }
+
+// IsSum is false.
+func (Seq) IsSum() bool { return false }
+
+// AsSum returns None.
+func (Seq) AsSum() option.Option[Sum] { return option.None[Sum]() }
+
+// IsTimeout is false.
+func (Seq) IsTimeout() bool { return false }
+
+// AsTimeout returns None.
+func (Seq) AsTimeout() option.Option[Timeout] { return option.None[Timeout]() }
+
+// IsSeq is true.
+func (Seq) IsSeq() bool { return true }
+
+// AsSeq returns the value.
+func (self Seq) AsSeq() option.Option[Seq] { return option.Some(self) }
+
+// String returns Seq(value).
+func (self Seq) String() string {
+	type plain Seq
+	return fmt.Sprintf("Seq(%+v)", plain(self))
+}
+
+// MarshalJSON encodes the value as {"Seq": value}.
+func (self Seq) MarshalJSON() ([]byte, error) {
+	type plain Seq
+	return json.Marshal(map[string]plain{"Seq": plain(self)})
+}
+
+// UnmarshalJSON decodes the value from {"Seq": value}.
+func (self *Seq) UnmarshalJSON(data []byte) error {
+	var tagged map[string]json.RawMessage
+	if err := json.Unmarshal(data, &tagged); err != nil {
+		return err
+	}
+	value, ok := tagged["Seq"]
+	if !ok || len(tagged) != 1 {
+		return fmt.Errorf("Seq: expected {\"Seq\": value}")
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(value, &items); err != nil {
+		return err
+	}
+	*self = nil
+	for _, item := range items {
+		decoded, err := UnmarshalExprJson(item).UnwrapWithError()
+		if err != nil {
+			return err
+		}
+		*self = append(*self, decoded)
+	}
+	return nil
+}
+
+// MatchExpr calls the function for the variant of the value.
+// A pointer to a variant is matched as the variant.
+//
+// Panics if the value or the pointer is nil, or if the value is not a variant.
+func MatchExpr[U any](value Expr, onNum func(Num) U, onNeg func(Neg) U, onSum func(Sum) U, onTimeout func(Timeout) U, onSeq func(Seq) U) U {
+	switch v := value.(type) {
+	case Num:
+		return onNum(v)
+	case *Num:
+		if v == nil {
+			panic("MatchExpr: nil *Num")
+		}
+		return onNum(*v)
+	case Neg:
+		return onNeg(v)
+	case *Neg:
+		if v == nil {
+			panic("MatchExpr: nil *Neg")
+		}
+		return onNeg(*v)
+	case Sum:
+		return onSum(v)
+	case *Sum:
+		if v == nil {
+			panic("MatchExpr: nil *Sum")
+		}
+		return onSum(*v)
+	case Timeout:
+		return onTimeout(v)
+	case *Timeout:
+		if v == nil {
+			panic("MatchExpr: nil *Timeout")
+		}
+		return onTimeout(*v)
+	case Seq:
+		return onSeq(v)
+	case *Seq:
+		if v == nil {
+			panic("MatchExpr: nil *Seq")
+		}
+		return onSeq(*v)
+	case nil:
+		panic("MatchExpr: nil Expr")
+	}
+	panic(fmt.Sprintf("MatchExpr: %T is not a variant of Expr", value))
+}
+
+// UnmarshalExprJson decodes Expr from {"Variant": value}.
+func UnmarshalExprJson(data []byte) result.Result[Expr] {
+	var tagged map[string]json.RawMessage
+	if err := json.Unmarshal(data, &tagged); err != nil {
+		return result.Err[Expr](err)
+	}
+	if len(tagged) != 1 {
+		return result.Err[Expr](fmt.Errorf("Expr: expected one variant, got %d", len(tagged)))
+	}
+	var tag string
+	for tag = range tagged {
+	}
+	switch tag {
+	case "Num":
+		var v Num
+		err := json.Unmarshal(data, &v)
+		return result.Wrap[Expr](v, err)
+	case "Neg":
+		var v Neg
+		err := json.Unmarshal(data, &v)
+		return result.Wrap[Expr](v, err)
+	case "Sum":
+		var v Sum
+		err := json.Unmarshal(data, &v)
+		return result.Wrap[Expr](v, err)
+	case "Timeout":
+		var v Timeout
+		err := json.Unmarshal(data, &v)
+		return result.Wrap[Expr](v, err)
+	case "Seq":
+		var v Seq
+		err := json.Unmarshal(data, &v)
+		return result.Wrap[Expr](v, err)
+	}
+	return result.Err[Expr](fmt.Errorf("Expr: unknown variant %q", tag))
+}
@@ -0,0 +1,91 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+// Package shape declares the enums generated by the tests of rusty-enum
+package shape
+
+import (
+	"math"
+	"time"
+)
+
+//go:generate go run -C ../../.. ./rusty-enum -C rusty-enum/testdata/shape
+
+// The enum declared by the spec comment
+//
+//rusty:enum Shape Circle Rect Dot
+
+type Circle struct {
+	Radius float64 `json:"radius"`
+}
+
+type Rect struct {
+	Width, Height float64
+}
+
+type Dot struct{}
+
+func (self Circle) Area() float64 { return math.Pi * self.Radius * self.Radius }
+
+// Rect has its own String
+func (self Rect) String() string { return "Rect" }
+
+// The enum declared by the marker interface
+//
+//rusty:enum Expr
+type expr interface {
+	isExpr()
+}
+
+type Num float64
+
+type Neg struct {
+	X Expr
+}
+
+type Sum struct {
+	Terms []Expr `json:"terms"`
+	// Not encoded
+	cached float64
+}
+
+type Timeout struct {
+	time.Duration
+	Fallback Expr `json:"fallback,omitempty"`
+}
+
+type Seq []Expr
+
+func (Num) isExpr()     {}
+func (Neg) isExpr()     {}
+func (Sum) isExpr()     {}
+func (Timeout) isExpr() {}
+func (Seq) isExpr()     {}
+
+// Not a variant, the marker has a pointer receiver
+type Pointer struct{}
+
+func (*Pointer) isExpr() {}
+
+// Evaluates the expression
+func Eval(e Expr) float64 {
+	return MatchExpr(e,
+		func(n Num) float64 { return float64(n) },
+		func(n Neg) float64 { return -Eval(n.X) },
+		func(s Sum) float64 {
+			total := 0.0
+			for _, t := range s.Terms {
+				total += Eval(t)
+			}
+			return total
+		},
+		func(t Timeout) float64 { return t.Seconds() },
+		func(s Seq) float64 {
+			if len(s) == 0 {
+				return 0
+			}
+			return Eval(s[len(s)-1])
+		},
+	)
+}
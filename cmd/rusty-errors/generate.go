@@ -0,0 +1,519 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/packages"
+)
+
+const (
+	resultPath = "github.com/pakuula/go-rusty/result"
+	directive  = "//rusty:error"
+)
+
+var (
+	ErrLoad      = errors.New("failed to load the package")
+	ErrNotFound  = errors.New("no //rusty:error directives")
+	ErrDirective = errors.New("invalid //rusty:error directive")
+	ErrTemplate  = errors.New("invalid message template")
+)
+
+// Settings of the generator
+type Config struct {
+	// The file with the annotated error types
+	File string
+	// The generated file, its old content is ignored
+	Output string
+	// Command line arguments recorded in the header
+	Args string
+}
+
+// An error type declared by a directive
+type errorType struct {
+	obj    *types.TypeName
+	fields []*field
+	// The format and the arguments of Error
+	format string
+	args   []string
+	// The format and the arguments for the nil source, empty if the message does not show it
+	nilFormat string
+	nilArgs   []string
+	// The sentinels matched by Is
+	sentinels []string
+	source    *field
+}
+
+type field struct {
+	name, param, typ string
+	// The type of the field is an interface or a pointer
+	nilable bool
+}
+
+// Loads the package of the file and returns the formatted source of the error methods
+func Generate(cfg Config) ([]byte, error) {
+	file, err := filepath.Abs(cfg.File)
+	if err != nil {
+		return nil, err
+	}
+	// The previous output may be stale
+	overlay := map[string][]byte{}
+	if cfg.Output != "" {
+		output, err := filepath.Abs(cfg.Output)
+		if err != nil {
+			return nil, err
+		}
+		if f, err := parser.ParseFile(token.NewFileSet(), output, nil, parser.PackageClauseOnly); err == nil {
+			overlay[output] = []byte("package " + f.Name.Name + "\n")
+		}
+	}
+	mode := packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo
+	pkgs, err := packages.Load(&packages.Config{Mode: mode, Dir: filepath.Dir(file), Overlay: overlay}, ".")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrLoad, err)
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("%w: expected one package in %s, got %d", ErrLoad, filepath.Dir(file), len(pkgs))
+	}
+	pkg := pkgs[0]
+	// The code using the errors does not type-check until they are generated
+	for _, e := range pkg.Errors {
+		if e.Kind == packages.ParseError || len(pkg.Syntax) == 0 {
+			return nil, fmt.Errorf("%w: %v", ErrLoad, e)
+		}
+	}
+	var syntax *ast.File
+	for _, f := range pkg.Syntax {
+		if pkg.Fset.File(f.Pos()).Name() == file {
+			syntax = f
+		}
+	}
+	if syntax == nil {
+		return nil, fmt.Errorf("%w: %s is not a file of the package %s", ErrLoad, cfg.File, pkg.PkgPath)
+	}
+
+	g := &generator{Config: cfg, fset: pkg.Fset, pkg: pkg.Types, info: pkg.TypesInfo, file: syntax, imports: map[string]string{}}
+	errs, err := g.parseFile()
+	if err != nil {
+		return nil, err
+	}
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("%s: %w", cfg.File, ErrNotFound)
+	}
+	for _, e := range errs {
+		g.errorType(e)
+	}
+	g.register(errs)
+	return g.source()
+}
+
+type generator struct {
+	Config
+	fset    *token.FileSet
+	pkg     *types.Package
+	info    *types.Info
+	file    *ast.File
+	imports map[string]string // import path -> package name
+	body    bytes.Buffer
+}
+
+// Finds the annotated types of the file
+func (self *generator) parseFile() ([]*errorType, error) {
+	var errs []*errorType
+	for _, decl := range self.file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			spec := spec.(*ast.TypeSpec)
+			doc := spec.Doc
+			if doc == nil && len(gen.Specs) == 1 {
+				doc = gen.Doc
+			}
+			if doc == nil {
+				continue
+			}
+			for _, c := range doc.List {
+				rest, ok := strings.CutPrefix(c.Text, directive)
+				if !ok || rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+					continue
+				}
+				e, err := self.parse(c.Pos(), strings.TrimSpace(rest), spec)
+				if err != nil {
+					return nil, err
+				}
+				errs = append(errs, e)
+			}
+		}
+	}
+	return errs, nil
+}
+
+// Parses the arguments of the directive: the quoted template and is=Sentinel options
+func (self *generator) parse(pos token.Pos, args string, spec *ast.TypeSpec) (*errorType, error) {
+	obj, ok := self.info.Defs[spec.Name].(*types.TypeName)
+	if !ok {
+		return nil, self.errorf(pos, ErrDirective, "%s is not a type", spec.Name.Name)
+	}
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok || spec.TypeParams != nil || spec.Assign.IsValid() {
+		return nil, self.errorf(pos, ErrDirective, "%s is not a non-generic struct", obj.Name())
+	}
+	for _, name := range []string{"Error", "Unwrap", "Is"} {
+		if m, _, _ := types.LookupFieldOrMethod(types.NewPointer(obj.Type()), false, self.pkg, name); m != nil {
+			return nil, self.errorf(pos, ErrDirective, "%s already has %s", obj.Name(), name)
+		}
+	}
+	e := &errorType{obj: obj}
+	if self.pkg.Scope().Lookup(e.constructor()) != nil {
+		return nil, self.errorf(pos, ErrDirective, "%s is already declared", e.constructor())
+	}
+
+	quoted, err := strconv.QuotedPrefix(args)
+	if err != nil {
+		return nil, self.errorf(pos, ErrDirective, "expected the quoted message")
+	}
+	template, _ := strconv.Unquote(quoted)
+
+	taken := map[string]bool{}
+	for _, f := range st.Fields.List {
+		names := make([]string, len(f.Names))
+		for i, name := range f.Names {
+			names[i] = name.Name
+		}
+		if len(names) == 0 {
+			names = []string{embeddedName(f.Type)}
+		}
+		isSource := false
+		if f.Tag != nil {
+			tag, _ := strconv.Unquote(f.Tag.Value)
+			isSource = reflect.StructTag(tag).Get("rusty") == "source"
+		}
+		for _, name := range names {
+			fd := &field{name: name, param: paramName(name), typ: self.typeString(f.Type)}
+			for taken[fd.param] {
+				fd.param += "_"
+			}
+			taken[fd.param] = true
+			e.fields = append(e.fields, fd)
+			if isSource || name == "Source" {
+				if e.source != nil {
+					return nil, self.errorf(pos, ErrDirective, "%s has two sources, %s and %s", obj.Name(), e.source.name, name)
+				}
+				t := self.info.TypeOf(f.Type)
+				if t == nil || !types.Implements(t, errorInterface) {
+					return nil, self.errorf(pos, ErrDirective, "the source %s.%s is not an error", obj.Name(), name)
+				}
+				switch t.Underlying().(type) {
+				case *types.Interface, *types.Pointer:
+					fd.nilable = true
+				}
+				e.source = fd
+			}
+		}
+	}
+
+	if e.format, e.args, err = e.parseTemplate(template, nil); err != nil {
+		return nil, self.errorf(pos, ErrTemplate, "%v", err)
+	}
+	if e.source != nil && e.source.nilable {
+		e.nilFormat, e.nilArgs, _ = e.parseTemplate(template, e.source)
+		if e.nilFormat == e.format {
+			e.nilFormat = ""
+		}
+	}
+
+	for _, opt := range strings.Fields(args[len(quoted):]) {
+		sentinel, ok := strings.CutPrefix(opt, "is=")
+		if !ok {
+			return nil, self.errorf(pos, ErrDirective, "unknown option %q", opt)
+		}
+		expr, err := self.sentinel(sentinel)
+		if err != nil {
+			return nil, self.errorf(pos, ErrDirective, "%v", err)
+		}
+		e.sentinels = append(e.sentinels, expr)
+	}
+	return e, nil
+}
+
+var errorInterface = types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+
+// Returns the name of the constructor: ErrNotFound for NotFound and NotFoundError
+func (self *errorType) constructor() string {
+	name := self.obj.Name()
+	if trimmed := strings.TrimSuffix(name, "Error"); trimmed != "" {
+		name = trimmed
+	}
+	r, size := utf8.DecodeRuneInString(name)
+	return "Err" + string(unicode.ToUpper(r)) + name[size:]
+}
+
+// Converts the template into the format and the arguments of fmt.Sprintf.
+// The placeholder {field} is formatted with %v, {field:verb} with %verb,
+// {{ and }} are the braces. The placeholder of the omitted field is dropped
+// with the separator before it, so "read {path}: {source}" becomes "read %v".
+func (self *errorType) parseTemplate(template string, omitted *field) (string, []string, error) {
+	var (
+		format strings.Builder
+		args   []string
+	)
+	for i := 0; i < len(template); i++ {
+		c := template[i]
+		switch {
+		case c == '{' && strings.HasPrefix(template[i:], "{{"):
+			format.WriteByte('{')
+			i++
+		case c == '}' && strings.HasPrefix(template[i:], "}}"):
+			format.WriteByte('}')
+			i++
+		case c == '{':
+			end := strings.IndexByte(template[i:], '}')
+			if end < 0 {
+				return "", nil, fmt.Errorf("unclosed { in %q", template)
+			}
+			name, verb, _ := strings.Cut(template[i+1:i+end], ":")
+			f := self.field(name)
+			if f == nil {
+				return "", nil, fmt.Errorf("%s has no field %s", self.obj.Name(), name)
+			}
+			if verb == "" {
+				verb = "v"
+			}
+			if f == omitted {
+				before := strings.TrimRight(format.String(), " :;,-")
+				format.Reset()
+				format.WriteString(before)
+				i += end
+				continue
+			}
+			format.WriteString("%" + verb)
+			args = append(args, "self."+f.name)
+			i += end
+		case c == '}':
+			return "", nil, fmt.Errorf("unmatched } in %q", template)
+		case c == '%':
+			format.WriteString("%%")
+		default:
+			format.WriteByte(c)
+		}
+	}
+	return format.String(), args, nil
+}
+
+// Returns the field by its name or the name with the lowercase first letter
+func (self *errorType) field(name string) *field {
+	for _, f := range self.fields {
+		if f.name == name {
+			return f
+		}
+	}
+	for _, f := range self.fields {
+		if paramName(f.name) == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// Resolves the sentinel error in the scope of the file
+func (self *generator) sentinel(name string) (string, error) {
+	var obj types.Object
+	if pkgName, sel, ok := strings.Cut(name, "."); ok {
+		for _, spec := range self.file.Imports {
+			if imported := self.info.PkgNameOf(spec); imported != nil && imported.Name() == pkgName {
+				obj = imported.Imported().Scope().Lookup(sel)
+				if obj != nil {
+					name = self.use(imported.Imported()) + "." + sel
+				}
+			}
+		}
+	} else {
+		obj = self.pkg.Scope().Lookup(name)
+	}
+	if _, ok := obj.(*types.Var); !ok || !types.Implements(obj.Type(), errorInterface) {
+		return "", fmt.Errorf("%s is not an error variable", name)
+	}
+	return name, nil
+}
+
+// Returns the name of the embedded field of the type
+func embeddedName(t ast.Expr) string {
+	for {
+		switch e := t.(type) {
+		case *ast.StarExpr:
+			t = e.X
+		case *ast.SelectorExpr:
+			t = e.Sel
+		case *ast.IndexExpr:
+			t = e.X
+		case *ast.IndexListExpr:
+			t = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return "_"
+		}
+	}
+}
+
+func paramName(field string) string {
+	r, size := utf8.DecodeRuneInString(field)
+	name := string(unicode.ToLower(r)) + field[size:]
+	if token.IsKeyword(name) || name == "_" {
+		name += "_"
+	}
+	return name
+}
+
+func (self *generator) errorf(pos token.Pos, kind error, format string, args ...any) error {
+	return fmt.Errorf("%s: %w: %s", self.fset.Position(pos), kind, fmt.Sprintf(format, args...))
+}
+
+// Returns the name of the package in the generated file
+func (self *generator) use(pkg *types.Package) string {
+	if pkg == self.pkg {
+		return ""
+	}
+	if name, ok := self.imports[pkg.Path()]; ok {
+		return name
+	}
+	name := pkg.Name()
+	taken := map[string]bool{}
+	for _, other := range self.imports {
+		taken[other] = true
+	}
+	for i := 2; taken[name]; i++ {
+		name = fmt.Sprintf("%s%d", pkg.Name(), i)
+	}
+	self.imports[pkg.Path()] = name
+	return name
+}
+
+func (self *generator) lib(importPath string) string {
+	return self.use(types.NewPackage(importPath, path.Base(importPath)))
+}
+
+// Returns the type expression in the generated file
+func (self *generator) typeString(expr ast.Expr) string {
+	if t := self.info.TypeOf(expr); t != nil && !strings.Contains(t.String(), "invalid type") {
+		return types.TypeString(t, self.use)
+	}
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			if pkgName, ok := self.info.Uses[id].(*types.PkgName); ok {
+				self.use(pkgName.Imported())
+			}
+		}
+		return true
+	})
+	return types.ExprString(expr)
+}
+
+func (self *generator) errorType(e *errorType) {
+	name := e.obj.Name()
+	fmt.Fprintf(&self.body, "// Error returns the message of %s.\n", name)
+	switch {
+	case len(e.args) == 0:
+		fmt.Fprintf(&self.body, "func (%s) Error() string {\nreturn %s\n}\n\n", name, self.sprintf(e.format, nil))
+	case e.nilFormat != "":
+		fmt.Fprintf(&self.body, "func (self %s) Error() string {\nif self.%s == nil {\nreturn %s\n}\nreturn %s\n}\n\n",
+			name, e.source.name, self.sprintf(e.nilFormat, e.nilArgs), self.sprintf(e.format, e.args))
+	default:
+		fmt.Fprintf(&self.body, "func (self %s) Error() string {\nreturn %s\n}\n\n", name, self.sprintf(e.format, e.args))
+	}
+
+	if e.source != nil {
+		fmt.Fprintf(&self.body, "// Unwrap returns the source of %s.\n", name)
+		fmt.Fprintf(&self.body, "func (self %s) Unwrap() error {\nreturn self.%s\n}\n\n", name, e.source.name)
+	}
+
+	if len(e.sentinels) > 0 {
+		checks := make([]string, len(e.sentinels))
+		for i, s := range e.sentinels {
+			checks[i] = "target == " + s
+		}
+		fmt.Fprintf(&self.body, "// Is reports if the target is %s.\n", strings.Join(e.sentinels, " or "))
+		fmt.Fprintf(&self.body, "func (%s) Is(target error) bool {\nreturn %s\n}\n\n", name, strings.Join(checks, " || "))
+	}
+
+	params, values := make([]string, len(e.fields)), make([]string, len(e.fields))
+	for i, f := range e.fields {
+		params[i] = f.param + " " + f.typ
+		values[i] = f.name + ": " + f.param
+	}
+	result := self.lib(resultPath)
+	fmt.Fprintf(&self.body, "// %s returns %s as the error of Result[T].\n", e.constructor(), name)
+	fmt.Fprintf(&self.body, "func %s[T any](%s) %s.Result[T] {\nreturn %s.Err[T](%s{%s})\n}\n\n",
+		e.constructor(), strings.Join(params, ", "), result, result, name, strings.Join(values, ", "))
+}
+
+// Returns the call of fmt.Sprintf or the string literal without arguments
+func (self *generator) sprintf(format string, args []string) string {
+	if len(args) == 0 {
+		return strconv.Quote(strings.ReplaceAll(format, "%%", "%"))
+	}
+	return fmt.Sprintf("%s.Sprintf(%q, %s)", self.lib("fmt"), format, strings.Join(args, ", "))
+}
+
+// Registers the error types with the Result JSON error registry
+func (self *generator) register(errs []*errorType) {
+	result := self.lib(resultPath)
+	fmt.Fprintf(&self.body, "func init() {\n")
+	for _, e := range errs {
+		fmt.Fprintf(&self.body, "%s.RegisterError[%s](%q)\n", result, e.obj.Name(), self.pkg.Path()+"."+e.obj.Name())
+	}
+	fmt.Fprintf(&self.body, "}\n")
+}
+
+func (self *generator) source() ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by rusty-errors %s; DO NOT EDIT.\n\n", self.Args)
+	fmt.Fprintf(&buf, "package %s\n\n", self.pkg.Name())
+
+	paths := make([]string, 0, len(self.imports))
+	for p := range self.imports {
+		paths = append(paths, p)
+	}
+	// Standard library first, then the rest
+	sort.Slice(paths, func(i, j int) bool {
+		iStd, jStd := !strings.Contains(paths[i], "."), !strings.Contains(paths[j], ".")
+		if iStd != jStd {
+			return iStd
+		}
+		return paths[i] < paths[j]
+	})
+	buf.WriteString("import (\n")
+	for i, p := range paths {
+		if i > 0 && strings.Contains(p, ".") && !strings.Contains(paths[i-1], ".") {
+			buf.WriteString("\n")
+		}
+		if name := self.imports[p]; name != path.Base(p) {
+			fmt.Fprintf(&buf, "%s %q\n", name, p)
+		} else {
+			fmt.Fprintf(&buf, "%q\n", p)
+		}
+	}
+	buf.WriteString(")\n\n")
+	buf.Write(self.body.Bytes())
+	return format.Source(buf.Bytes())
+}
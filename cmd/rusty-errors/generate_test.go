@@ -0,0 +1,142 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/pakuula/go-rusty/cmd/rusty-errors/testdata/fserr"
+	"github.com/pakuula/go-rusty/result"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+const golden = "testdata/fserr/errors_gen.go"
+
+func TestGenerate(t *testing.T) {
+	src, err := Generate(Config{File: "testdata/fserr/errors.go", Output: golden, Args: "rusty-errors/testdata/fserr/errors.go"})
+	require.NoError(t, err)
+	if *update {
+		require.NoError(t, os.WriteFile(golden, src, 0o644))
+	}
+	expected, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(src))
+}
+
+func TestErrors(t *testing.T) {
+	for name, expected := range map[string]error{
+		"testdata/bad/field.go":    ErrTemplate,
+		"testdata/bad/kind.go":     ErrDirective,
+		"testdata/bad/quote.go":    ErrDirective,
+		"testdata/bad/sentinel.go": ErrDirective,
+		"testdata/bad/source.go":   ErrDirective,
+		"testdata/bad/none.go":     ErrNotFound,
+		"testdata/bad/missing.go":  ErrLoad,
+	} {
+		_, err := Generate(Config{File: name})
+		assert.ErrorIs(t, err, expected, name)
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	e := &errorType{obj: types.NewTypeName(token.NoPos, nil, "Located", nil), fields: []*field{{name: "Path"}, {name: "Line"}}}
+	format, args, err := e.parseTemplate("{path:q}:{Line:03d} 5% {{x}}", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "%q:%03d 5%% {x}", format)
+	assert.Equal(t, []string{"self.Path", "self.Line"}, args)
+
+	for _, template := range []string{"{path", "path}", "{name}"} {
+		_, _, err := e.parseTemplate(template, nil)
+		assert.Error(t, err, template)
+	}
+
+	// The omitted field is dropped with the separator
+	format, args, err = e.parseTemplate("read {path}: {line} (eof)", e.fields[1])
+	require.NoError(t, err)
+	assert.Equal(t, "read %v (eof)", format)
+	assert.Equal(t, []string{"self.Path"}, args)
+}
+
+func TestConstructor(t *testing.T) {
+	for name, expected := range map[string]string{
+		"NotFoundError": "ErrNotFound",
+		"Timeout":       "ErrTimeout",
+		"Error":         "ErrError",
+		"parseError":    "ErrParse",
+	} {
+		e := &errorType{obj: types.NewTypeName(token.NoPos, nil, name, nil)}
+		assert.Equal(t, expected, e.constructor())
+	}
+}
+
+type deniedFS struct{}
+
+func (deniedFS) Open(string) (fs.File, error) { return nil, fs.ErrPermission }
+
+func TestGenerated(t *testing.T) {
+	fsys := fstest.MapFS{"a.txt": &fstest.MapFile{Data: []byte("a")}}
+	assert.True(t, fserr.Stat(fsys, "a.txt").IsValue())
+
+	err := fserr.Stat(fsys, "b.txt").Err()
+	assert.EqualError(t, err, "file b.txt not found")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	var notFound fserr.NotFoundError
+	require.ErrorAs(t, err, &notFound)
+	assert.Equal(t, "b.txt", notFound.Path)
+
+	err = fserr.Stat(deniedFS{}, "a.txt").Err()
+	assert.EqualError(t, err, `read "a.txt": permission denied`)
+	var read fserr.ReadError
+	require.ErrorAs(t, err, &read)
+	assert.ErrorIs(t, err, fs.ErrPermission)
+
+	cause := errors.New("unexpected EOF")
+	err = fserr.ErrParse[int](3, "no value", cause).Err()
+	assert.EqualError(t, err, "line 3: no value (100% sure, {braces})")
+	assert.ErrorIs(t, err, fserr.ErrConfig)
+	assert.ErrorIs(t, err, fs.ErrInvalid)
+	assert.ErrorIs(t, err, cause)
+	assert.NotErrorIs(t, err, fs.ErrNotExist)
+
+	assert.EqualError(t, fserr.ErrTimeout[string](time.Second).Err(), "timed out")
+
+	// The nil source is not shown
+	assert.EqualError(t, fserr.ErrRead[int]("a.txt", nil).Err(), `read "a.txt"`)
+	assert.NoError(t, errors.Unwrap(fserr.ErrRead[int]("a.txt", nil).Err()))
+}
+
+func TestRegistered(t *testing.T) {
+	data, err := json.Marshal(fserr.ErrNotFound[int]("b.txt"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"error": {
+		"type": "github.com/pakuula/go-rusty/cmd/rusty-errors/testdata/fserr.NotFoundError",
+		"message": "file b.txt not found",
+		"data": {"Path": "b.txt"}
+	}}`, string(data))
+
+	var decoded result.Result[int]
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, fserr.NotFoundError{Path: "b.txt"}, decoded.Err())
+	assert.ErrorIs(t, decoded.Err(), fs.ErrNotExist)
+
+	// The source is not encoded
+	data, err = json.Marshal(fserr.ErrRead[int]("a.txt", fs.ErrPermission))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, fserr.ReadError{Path: "a.txt"}, decoded.Err())
+}
@@ -0,0 +1,67 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+// Command rusty-errors generates the methods of error types
+// from their messages, like the thiserror crate of Rust.
+//
+// The directive on a struct gives the message, the placeholders refer to the fields:
+//
+//	//rusty:error "read {path}: {source}" is=fs.ErrInvalid
+//	type ReadError struct {
+//		Path   string
+//		Source error
+//	}
+//
+// The tool generates
+//   - Error formatting the message, {field:verb} uses the verb instead of %v;
+//     a nil source is dropped from the message with the separator before it,
+//   - Unwrap returning the field Source or the field tagged `rusty:"source"`,
+//   - Is matching the sentinel errors of the is= options,
+//   - the constructor ErrRead[T](path, source) returning result.Result[T] with the error,
+//   - init registering the types by result.RegisterError for the JSON encoding of Result.
+//
+// Usage:
+//
+//	rusty-errors [-o errors_gen.go] errors.go
+//
+// The tool is meant to be run by go generate:
+//
+//	//go:generate go run github.com/pakuula/go-rusty/cmd/rusty-errors $GOFILE
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	var cfg Config
+	flag.StringVar(&cfg.Output, "o", "", "output file (default <file>_gen.go)")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	cfg.File = flag.Arg(0)
+	if cfg.Output == "" {
+		cfg.Output = strings.TrimSuffix(cfg.File, ".go") + "_gen.go"
+	}
+	cfg.Args = strings.Join(os.Args[1:], " ")
+
+	src, err := Generate(cfg)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	if err := os.WriteFile(cfg.Output, src, 0o644); err != nil {
+		fatalf("%v", err)
+	}
+}
+
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "rusty-errors: "+format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,58 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+// Package fserr declares the errors generated by the tests of rusty-errors
+package fserr
+
+import (
+	"errors"
+	"io/fs"
+	"time"
+
+	"github.com/pakuula/go-rusty/result"
+)
+
+//go:generate go run -C ../../.. ./rusty-errors rusty-errors/testdata/fserr/errors.go
+
+var ErrConfig = errors.New("invalid config")
+
+//rusty:error "file {path} not found" is=fs.ErrNotExist
+type NotFoundError struct {
+	Path string
+}
+
+//rusty:error "read {Path:q}: {source}"
+type ReadError struct {
+	Path   string
+	Source error
+}
+
+//rusty:error "line {line}: {msg} (100% sure, {{braces}})" is=ErrConfig is=fs.ErrInvalid
+type ParseError struct {
+	Line  int
+	Msg   string
+	cause error `rusty:"source"`
+}
+
+// The message without placeholders
+//
+//rusty:error "timed out"
+type Timeout struct {
+	time.Duration
+}
+
+// Not annotated
+type Plain struct{}
+
+// Returns the file info, the errors are NotFoundError and ReadError
+func Stat(fsys fs.FS, path string) result.Result[fs.FileInfo] {
+	info, err := fs.Stat(fsys, path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrNotFound[fs.FileInfo](path)
+	}
+	if err != nil {
+		return ErrRead[fs.FileInfo](path, err)
+	}
+	return result.Val(info)
+}
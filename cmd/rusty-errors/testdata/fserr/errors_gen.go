@@ -0,0 +1,81 @@
+// Code generated by rusty-errors rusty-errors/testdata/fserr/errors.go; DO NOT EDIT.
+
+package fserr
+
+import (
+	"fmt"
+	"io/fs"
+	"time"
+
+	"github.com/pakuula/go-rusty/result"
+)
+
+// Error returns the message of NotFoundError.
+func (self NotFoundError) Error() string {
+	return fmt.Sprintf("file %v not found", self.Path)
+}
+
+// Is reports if the target is fs.ErrNotExist.
+func (NotFoundError) Is(target error) bool {
+	return target == fs.ErrNotExist
+}
+
+// ErrNotFound returns NotFoundError as the error of Result[T].
+func ErrNotFound[T any](path string) result.Result[T] {
+	return result.Err[T](NotFoundError{Path: path})
+}
+
+// Error returns the message of ReadError.
+func (self ReadError) Error() string {
+	if self.Source == nil {
+		return fmt.Sprintf("read %q", self.Path)
+	}
+	return fmt.Sprintf("read %q: %v", self.Path, self.Source)
+}
+
+// Unwrap returns the source of ReadError.
+func (self ReadError) Unwrap() error {
+	return self.Source
+}
+
+// ErrRead returns ReadError as the error of Result[T].
+func ErrRead[T any](path string, source error) result.Result[T] {
+	return result.Err[T](ReadError{Path: path, Source: source})
+}
+
+// Error returns the message of ParseError.
+func (self ParseError) Error() string {
+	return fmt.Sprintf("line %v: %v (100%% sure, {braces})", self.Line, self.Msg)
+}
+
+// Unwrap returns the source of ParseError.
+func (self ParseError) Unwrap() error {
+	return self.cause
+}
+
+// Is reports if the target is ErrConfig or fs.ErrInvalid.
+func (ParseError) Is(target error) bool {
+	return target == ErrConfig || target == fs.ErrInvalid
+}
+
+// ErrParse returns ParseError as the error of Result[T].
+func ErrParse[T any](line int, msg string, cause error) result.Result[T] {
+	return result.Err[T](ParseError{Line: line, Msg: msg, cause: cause})
+}
+
+// Error returns the message of Timeout.
+func (Timeout) Error() string {
+	return "timed out"
+}
+
+// ErrTimeout returns Timeout as the error of Result[T].
+func ErrTimeout[T any](duration time.Duration) result.Result[T] {
+	return result.Err[T](Timeout{Duration: duration})
+}
+
+func init() {
+	result.RegisterError[NotFoundError]("github.com/pakuula/go-rusty/cmd/rusty-errors/testdata/fserr.NotFoundError")
+	result.RegisterError[ReadError]("github.com/pakuula/go-rusty/cmd/rusty-errors/testdata/fserr.ReadError")
+	result.RegisterError[ParseError]("github.com/pakuula/go-rusty/cmd/rusty-errors/testdata/fserr.ParseError")
+	result.RegisterError[Timeout]("github.com/pakuula/go-rusty/cmd/rusty-errors/testdata/fserr.Timeout")
+}
@@ -0,0 +1,120 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+// Command rusty-migrate rewrites functions between the if-err style
+// and the Result style.
+//
+// With -to result the functions returning (T, error) or error return
+// result.Result[T] or result.ResultVoid. The error checks
+//
+//	x, err := f()
+//	if err != nil {
+//		return zero, err
+//	}
+//
+// become x := result.Must(f()) and the function gets the named result res
+// with defer result.Catch(&res). With -to iferr the rewrite goes back.
+// The callers in the loaded packages are updated in both directions.
+// The functions that cannot be rewritten safely are reported and left as they are.
+//
+// The rewritten packages are type-checked. The tool prints a unified diff
+// unless -w is given.
+//
+// Usage:
+//
+//	rusty-migrate -to result [-w] [-run regexp] ./...
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+func main() {
+	var (
+		cfg   Config
+		to    string
+		run   string
+		write bool
+	)
+	flag.StringVar(&to, "to", "result", "the style to migrate to, result or iferr")
+	flag.StringVar(&run, "run", "", "rewrite only the functions whose names match the regexp")
+	flag.BoolVar(&write, "w", false, "write the changes to the files instead of printing the diff")
+	flag.Parse()
+
+	switch to {
+	case "result":
+		cfg.To = ToResult
+	case "iferr":
+		cfg.To = ToIfErr
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if run != "" {
+		re, err := regexp.Compile(run)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		cfg.Run = re
+	}
+	cfg.Patterns = flag.Args()
+	if len(cfg.Patterns) == 0 {
+		cfg.Patterns = []string{"."}
+	}
+	cfg.Warnf = func(format string, args ...any) {
+		fmt.Fprintf(os.Stderr, "rusty-migrate: "+format+"\n", args...)
+	}
+
+	changed, err := Migrate(cfg)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	names := make([]string, 0, len(changed))
+	for name := range changed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if write {
+			if err := os.WriteFile(name, changed[name], 0o644); err != nil {
+				fatalf("%v", err)
+			}
+			continue
+		}
+		old, err := os.ReadFile(name)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		fmt.Print(diff(name, old, changed[name]))
+	}
+}
+
+// Returns the unified diff of the file
+func diff(name string, old, new []byte) string {
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, name); err == nil {
+			name = rel
+		}
+	}
+	text, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(old)),
+		B:        difflib.SplitLines(string(new)),
+		FromFile: "a/" + filepath.ToSlash(name),
+		ToFile:   "b/" + filepath.ToSlash(name),
+		Context:  3,
+	})
+	return text
+}
+
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "rusty-migrate: "+format+"\n", args...)
+	os.Exit(1)
+}
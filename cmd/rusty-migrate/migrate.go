@@ -0,0 +1,569 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+	"golang.org/x/tools/go/packages"
+)
+
+const resultPath = "github.com/pakuula/go-rusty/result"
+
+var (
+	ErrLoad      = errors.New("packages contain errors")
+	ErrOverlap   = errors.New("overlapping edits")
+	ErrTypeCheck = errors.New("the rewritten code does not type-check")
+)
+
+// The direction of the rewrite
+type Direction int
+
+const (
+	// (T, error) functions become Result[T] functions
+	ToResult Direction = iota
+	// Result[T] functions become (T, error) functions
+	ToIfErr
+)
+
+// Settings of the migration
+type Config struct {
+	// Directory to load the packages from, the current one if empty
+	Dir string
+	// Package patterns, e.g. ./...
+	Patterns []string
+	To       Direction
+	// Only the functions whose names match are rewritten, all if nil
+	Run *regexp.Regexp
+	// Replaces the contents of the files, see packages.Config.Overlay
+	Overlay map[string][]byte
+	// Reports the functions that are left as they are, may be nil
+	Warnf func(format string, args ...any)
+}
+
+// Rewrites the packages and returns the new contents of the changed files
+func Migrate(cfg Config) (map[string][]byte, error) {
+	if cfg.Warnf == nil {
+		cfg.Warnf = func(string, ...any) {}
+	}
+	mode := packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles | packages.NeedImports |
+		packages.NeedDeps | packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo
+	loaded, err := packages.Load(&packages.Config{Mode: mode, Dir: cfg.Dir, Tests: true, Overlay: cfg.Overlay}, cfg.Patterns...)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.To == ToResult {
+		// The rewritten code may import the package for the first time
+		lib, err := packages.Load(&packages.Config{Mode: mode, Dir: cfg.Dir}, resultPath)
+		if err != nil {
+			return nil, err
+		}
+		loaded = append(loaded, lib...)
+	}
+	if packages.PrintErrors(loaded) > 0 {
+		return nil, ErrLoad
+	}
+
+	m := &migration{
+		Config:    cfg,
+		files:     map[string]*file{},
+		converted: map[string]*function{},
+		handled:   map[*ast.CallExpr]bool{},
+	}
+	m.selectPackages(loaded)
+	if len(m.pkgs) == 0 {
+		return map[string][]byte{}, nil
+	}
+	m.fset = m.pkgs[0].Fset
+	if err := m.readFiles(); err != nil {
+		return nil, err
+	}
+	if cfg.To == ToResult {
+		err = m.toResult()
+	} else {
+		err = m.toIfErr()
+	}
+	if err != nil {
+		return nil, err
+	}
+	changed, err := m.apply()
+	if err != nil {
+		return nil, err
+	}
+	if err := m.typeCheck(loaded, changed); err != nil {
+		return nil, err
+	}
+	return changed, nil
+}
+
+type migration struct {
+	Config
+	fset      *token.FileSet
+	pkgs      []*packages.Package // the packages to rewrite, in dependency order
+	files     map[string]*file    // by file name
+	converted map[string]*function
+	handled   map[*ast.CallExpr]bool // calls rewritten together with the enclosing statement
+}
+
+// A function whose signature is rewritten
+type function struct {
+	pkg  *packages.Package
+	file *file
+	decl *ast.FuncDecl
+	// The type T of (T, error) or Result[T], nil for error and ResultVoid
+	value types.Type
+	// The source text of T
+	valueText string
+}
+
+func (self *function) void() bool { return self.value == nil }
+
+// Selects the packages to rewrite, the result package is never rewritten.
+// The test variant of a package replaces the package, as it has the same files and more.
+func (self *migration) selectPackages(loaded []*packages.Package) {
+	hasTests := map[string]bool{}
+	for _, pkg := range loaded {
+		if pkg.ForTest != "" && pkg.PkgPath == pkg.ForTest {
+			hasTests[pkg.PkgPath] = true
+		}
+	}
+	// The dependencies of the result package cannot import it
+	deps := map[string]bool{}
+	packages.Visit(loaded, nil, func(pkg *packages.Package) {
+		if pkg.PkgPath == resultPath && self.To == ToResult {
+			packages.Visit([]*packages.Package{pkg}, nil, func(dep *packages.Package) {
+				deps[dep.PkgPath] = true
+			})
+		}
+	})
+	roots := map[*packages.Package]bool{}
+	for _, pkg := range loaded {
+		switch {
+		case pkg.PkgPath == resultPath || pkg.ForTest == resultPath || deps[pkg.PkgPath]:
+		case strings.HasSuffix(pkg.PkgPath, ".test"):
+		case pkg.ForTest == "" && hasTests[pkg.PkgPath]:
+		default:
+			roots[pkg] = true
+		}
+	}
+	packages.Visit(loaded, nil, func(pkg *packages.Package) {
+		if roots[pkg] {
+			self.pkgs = append(self.pkgs, pkg)
+			delete(roots, pkg)
+		}
+	})
+}
+
+// Visits the packages, their files and the function declarations. The generated files are skipped.
+func (self *migration) funcDecls(f func(pkg *packages.Package, file *file, decl *ast.FuncDecl)) {
+	for _, pkg := range self.pkgs {
+		for _, syntax := range pkg.Syntax {
+			if ast.IsGenerated(syntax) {
+				continue
+			}
+			file := self.file(pkg, syntax)
+			for _, decl := range syntax.Decls {
+				if decl, ok := decl.(*ast.FuncDecl); ok && decl.Recv == nil && decl.Body != nil {
+					if self.Run == nil || self.Run.MatchString(decl.Name.Name) {
+						f(pkg, file, decl)
+					}
+				}
+			}
+		}
+	}
+}
+
+// Drops the functions that are referred to other than by a call
+func (self *migration) onlyCalled() {
+	for _, pkg := range self.pkgs {
+		called := map[*ast.Ident]bool{}
+		for _, syntax := range pkg.Syntax {
+			ast.Inspect(syntax, func(n ast.Node) bool {
+				if call, ok := n.(*ast.CallExpr); ok {
+					if id := calleeIdent(call); id != nil {
+						called[id] = true
+					}
+				}
+				return true
+			})
+		}
+		for id, obj := range pkg.TypesInfo.Uses {
+			key := funcKey(obj)
+			if fn := self.converted[key]; fn != nil && !called[id] {
+				self.Warnf("%s: skipped, used as a value at %s", key, self.fset.Position(id.Pos()))
+				delete(self.converted, key)
+			}
+		}
+	}
+}
+
+// Returns the identifier of the called function
+func calleeIdent(call *ast.CallExpr) *ast.Ident {
+	fun := ast.Unparen(call.Fun)
+	switch f := fun.(type) {
+	case *ast.IndexExpr:
+		fun = f.X
+	case *ast.IndexListExpr:
+		fun = f.X
+	}
+	switch f := fun.(type) {
+	case *ast.Ident:
+		return f
+	case *ast.SelectorExpr:
+		return f.Sel
+	}
+	return nil
+}
+
+// Returns the converted function called by the expression
+func (self *migration) callee(info *types.Info, e ast.Expr) (*ast.CallExpr, *function) {
+	call, ok := ast.Unparen(e).(*ast.CallExpr)
+	if !ok {
+		return nil, nil
+	}
+	if id := calleeIdent(call); id != nil {
+		return call, self.converted[funcKey(info.Uses[id])]
+	}
+	return call, nil
+}
+
+// Returns the name of the function of the result package called by the expression
+func resultFunc(info *types.Info, e ast.Expr) (*ast.CallExpr, string) {
+	call, ok := ast.Unparen(e).(*ast.CallExpr)
+	if !ok {
+		return nil, ""
+	}
+	id := calleeIdent(call)
+	if id == nil {
+		return call, ""
+	}
+	fn, ok := info.Uses[id].(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Pkg().Path() != resultPath {
+		return call, ""
+	}
+	fn = fn.Origin()
+	if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+		return call, "Result." + fn.Name()
+	}
+	return call, fn.Name()
+}
+
+// Identifies package level functions across the test variants of the packages
+func funcKey(obj types.Object) string {
+	fn, ok := obj.(*types.Func)
+	if !ok || fn.Pkg() == nil || fn.Type().(*types.Signature).Recv() != nil {
+		return ""
+	}
+	return fn.Pkg().Path() + "." + fn.Name()
+}
+
+// Reports if the name is declared or used in the function
+func mentions(decl *ast.FuncDecl, name string) bool {
+	found := false
+	ast.Inspect(decl, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && id.Name == name && n != decl.Name {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// Visits the statement lists of the body, function literals are not entered
+func stmtLists(body *ast.BlockStmt, f func([]ast.Stmt)) {
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.BlockStmt:
+			f(n.List)
+		case *ast.CaseClause:
+			f(n.Body)
+		case *ast.CommClause:
+			f(n.Body)
+		case *ast.FuncLit:
+			return false
+		}
+		return true
+	})
+}
+
+// Visits the nodes of the body, function literals are not entered
+func inspectBody(body *ast.BlockStmt, f func(ast.Node)) {
+	ast.Inspect(body, func(n ast.Node) bool {
+		if n == nil {
+			return false
+		}
+		f(n)
+		_, isLit := n.(*ast.FuncLit)
+		return !isLit
+	})
+}
+
+// Source files
+
+type edit struct {
+	start, end int
+	text       string
+	consumed   bool // applied as a part of an enclosing edit
+}
+
+type file struct {
+	name       string
+	src        []byte
+	tf         *token.File
+	syntax     *ast.File
+	edits      []*edit
+	needResult bool // the edits refer to the result package
+}
+
+// Reads the files of the selected packages
+func (self *migration) readFiles() error {
+	for _, pkg := range self.pkgs {
+		for _, syntax := range pkg.Syntax {
+			tf := pkg.Fset.File(syntax.Pos())
+			if _, ok := self.files[tf.Name()]; ok {
+				continue
+			}
+			src, err := readFile(self.Overlay, tf.Name())
+			if err != nil {
+				return err
+			}
+			self.files[tf.Name()] = &file{name: tf.Name(), src: src, tf: tf, syntax: syntax}
+		}
+	}
+	return nil
+}
+
+func (self *migration) file(pkg *packages.Package, syntax *ast.File) *file {
+	return self.files[pkg.Fset.File(syntax.Pos()).Name()]
+}
+
+func readFile(overlay map[string][]byte, name string) ([]byte, error) {
+	if src, ok := overlay[name]; ok {
+		return src, nil
+	}
+	return os.ReadFile(name)
+}
+
+// Replaces the text between the positions
+func (self *file) replace(start, end token.Pos, text string) {
+	self.edits = append(self.edits, &edit{start: self.tf.Offset(start), end: self.tf.Offset(end), text: text})
+}
+
+// Returns the text between the positions with the edits inside applied
+func (self *file) render(start, end token.Pos) string {
+	return self.renderOffsets(self.tf.Offset(start), self.tf.Offset(end))
+}
+
+func (self *file) text(n ast.Node) string { return self.render(n.Pos(), n.End()) }
+
+func (self *file) renderOffsets(start, end int) string {
+	var inner []*edit
+	for _, e := range self.edits {
+		if !e.consumed && e.start >= start && e.end <= end {
+			inner = append(inner, e)
+		}
+	}
+	sort.SliceStable(inner, func(i, j int) bool { return inner[i].start < inner[j].start })
+	var buf strings.Builder
+	pos := start
+	for _, e := range inner {
+		if e.start < pos {
+			continue
+		}
+		buf.Write(self.src[pos:e.start])
+		buf.WriteString(e.text)
+		pos = e.end
+		e.consumed = true
+	}
+	buf.Write(self.src[pos:end])
+	return buf.String()
+}
+
+// Removes the statement with its line if nothing else is on it
+func (self *file) remove(stmt ast.Stmt) {
+	start, end := self.tf.Offset(stmt.Pos()), self.tf.Offset(stmt.End())
+	lineStart := bytes.LastIndexByte(self.src[:start], '\n') + 1
+	lineEnd := bytes.IndexByte(self.src[end:], '\n')
+	if lineEnd >= 0 && len(bytes.TrimSpace(self.src[lineStart:start])) == 0 && len(bytes.TrimSpace(self.src[end:end+lineEnd])) == 0 {
+		start, end = lineStart, end+lineEnd+1
+	}
+	self.edits = append(self.edits, &edit{start: start, end: end})
+}
+
+// Returns the name of the result package in the file, the import is added if needed
+func (self *file) result() string {
+	for _, spec := range self.syntax.Imports {
+		if strings.Trim(spec.Path.Value, `"`) == resultPath {
+			if spec.Name != nil {
+				return spec.Name.Name
+			}
+			return "result"
+		}
+	}
+	self.needResult = true
+	return "result"
+}
+
+// Applies the edits and formats the changed files
+func (self *migration) apply() (map[string][]byte, error) {
+	changed := map[string][]byte{}
+	for name, f := range self.files {
+		if len(f.edits) == 0 {
+			continue
+		}
+		src := []byte(f.renderOffsets(0, len(f.src)))
+		for _, e := range f.edits {
+			if !e.consumed {
+				return nil, fmt.Errorf("%s: %w", name, ErrOverlap)
+			}
+		}
+		fset := token.NewFileSet()
+		syntax, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrTypeCheck, err)
+		}
+		if f.needResult {
+			astutil.AddImport(fset, syntax, resultPath)
+		}
+		if !astutil.UsesImport(syntax, resultPath) && astutil.DeleteImport(fset, syntax, resultPath) {
+			unparenImports(syntax)
+		}
+		var buf bytes.Buffer
+		if err := format.Node(&buf, fset, syntax); err != nil {
+			return nil, err
+		}
+		if src, err = format.Source(buf.Bytes()); err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(src, f.src) {
+			changed[name] = src
+		}
+	}
+	return changed, nil
+}
+
+// Drops the parentheses around a single import
+func unparenImports(syntax *ast.File) {
+	for _, decl := range syntax.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT && len(gen.Specs) == 1 && gen.Lparen.IsValid() {
+			spec := gen.Specs[0].(*ast.ImportSpec)
+			if spec.Doc == nil && spec.Comment == nil {
+				gen.Lparen, gen.Rparen = token.NoPos, token.NoPos
+			}
+		}
+	}
+}
+
+// Type-checks the rewritten packages against the rewritten dependencies
+func (self *migration) typeCheck(loaded []*packages.Package, changed map[string][]byte) error {
+	all := map[string]*types.Package{}
+	packages.Visit(loaded, nil, func(pkg *packages.Package) {
+		if _, ok := all[pkg.PkgPath]; !ok && pkg.Types != nil {
+			all[pkg.PkgPath] = pkg.Types
+		}
+	})
+	for _, pkg := range self.pkgs {
+		all[pkg.PkgPath] = pkg.Types
+	}
+	for _, pkg := range self.pkgs {
+		dirty := false
+		for _, name := range pkg.CompiledGoFiles {
+			_, ok := changed[name]
+			dirty = dirty || ok
+		}
+		if !dirty {
+			continue
+		}
+		fset := token.NewFileSet()
+		var files []*ast.File
+		for _, name := range pkg.CompiledGoFiles {
+			src, ok := changed[name]
+			if !ok {
+				var err error
+				if src, err = readFile(self.Overlay, name); err != nil {
+					return err
+				}
+			}
+			syntax, err := parser.ParseFile(fset, name, src, 0)
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrTypeCheck, err)
+			}
+			files = append(files, syntax)
+		}
+		conf := types.Config{Importer: importerFunc(func(path string) (*types.Package, error) {
+			if path == "unsafe" {
+				return types.Unsafe, nil
+			}
+			if pkg, ok := all[path]; ok {
+				return pkg, nil
+			}
+			return nil, fmt.Errorf("package %s is not loaded", path)
+		})}
+		checked, err := conf.Check(pkg.PkgPath, fset, files, nil)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrTypeCheck, err)
+		}
+		all[pkg.PkgPath] = checked
+	}
+	return nil
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (self importerFunc) Import(path string) (*types.Package, error) { return self(path) }
+
+// Type expressions
+
+// Returns the zero value of the type as written in the package
+func zeroValue(t types.Type, qualifier types.Qualifier) string {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsBoolean != 0:
+			return "false"
+		case u.Info()&types.IsString != 0:
+			return `""`
+		case u.Info()&types.IsNumeric != 0:
+			return "0"
+		}
+		return "nil"
+	case *types.Pointer, *types.Slice, *types.Map, *types.Chan, *types.Signature:
+		return "nil"
+	case *types.Interface:
+		if _, isParam := t.(*types.TypeParam); !isParam {
+			return "nil"
+		}
+	case *types.Struct, *types.Array:
+		return types.TypeString(t, qualifier) + "{}"
+	}
+	return "*new(" + types.TypeString(t, qualifier) + ")"
+}
+
+// Qualifies the types by the names the file imports the packages with
+func (self *file) qualifier(pkg *types.Package) types.Qualifier {
+	return func(other *types.Package) string {
+		if other == pkg {
+			return ""
+		}
+		for _, spec := range self.syntax.Imports {
+			if strings.Trim(spec.Path.Value, `"`) == other.Path() && spec.Name != nil {
+				return spec.Name.Name
+			}
+		}
+		return other.Name()
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func migrate(t *testing.T, to Direction, overlay map[string][]byte, patterns ...string) (map[string][]byte, []string) {
+	t.Helper()
+	var warnings []string
+	changed, err := Migrate(Config{
+		Patterns: patterns,
+		To:       to,
+		Overlay:  overlay,
+		Warnf: func(format string, args ...any) {
+			warnings = append(warnings, fmt.Sprintf(format, args...))
+		},
+	})
+	require.NoError(t, err)
+	return changed, warnings
+}
+
+// Compares the changed files with the .golden files next to them
+func checkGolden(t *testing.T, changed map[string][]byte, names ...string) {
+	t.Helper()
+	require.Len(t, changed, len(names))
+	for _, name := range names {
+		path, err := filepath.Abs(name)
+		require.NoError(t, err)
+		src, ok := changed[path]
+		require.True(t, ok, name)
+		if *update {
+			require.NoError(t, os.WriteFile(name+".golden", src, 0o644))
+		}
+		expected, err := os.ReadFile(name + ".golden")
+		require.NoError(t, err)
+		assert.Equal(t, string(expected), string(src), name)
+	}
+}
+
+func TestToResult(t *testing.T) {
+	changed, warnings := migrate(t, ToResult, nil, "./testdata/iferr/...")
+	checkGolden(t, changed, "testdata/iferr/iferr.go", "testdata/iferr/use/use.go")
+	assert.ElementsMatch(t, []string{
+		"github.com/pakuula/go-rusty/cmd/rusty-migrate/testdata/iferr.Named: skipped, the name res is taken",
+		"github.com/pakuula/go-rusty/cmd/rusty-migrate/testdata/iferr.Callback: skipped, used as a value at " +
+			position(t, "testdata/iferr/iferr.go", 66, 43),
+	}, warnings)
+
+	// The second run changes nothing
+	again, _ := migrate(t, ToResult, changed, "./testdata/iferr/...")
+	assert.Empty(t, again)
+}
+
+func TestToIfErr(t *testing.T) {
+	changed, warnings := migrate(t, ToIfErr, nil, "./testdata/res")
+	checkGolden(t, changed, "testdata/res/res.go")
+	assert.ElementsMatch(t, []string{
+		"github.com/pakuula/go-rusty/cmd/rusty-migrate/testdata/res.Double: skipped, Result.Must at " +
+			position(t, "testdata/res/res.go", 50, 24) + " is not a statement",
+	}, warnings)
+
+	again, _ := migrate(t, ToIfErr, changed, "./testdata/res")
+	assert.Empty(t, again)
+}
+
+// The functions converted back and forth are the same
+func TestRoundTrip(t *testing.T) {
+	forward, _ := migrate(t, ToResult, nil, "./testdata/iferr/...")
+	back, _ := migrate(t, ToIfErr, forward, "./testdata/iferr/...")
+	for name, src := range back {
+		original, err := os.ReadFile(name)
+		require.NoError(t, err)
+		assert.Equal(t, string(original), string(src), name)
+	}
+}
+
+func TestRun(t *testing.T) {
+	var warnings []string
+	changed, err := Migrate(Config{
+		Patterns: []string{"./testdata/iferr/..."},
+		Run:      regexp.MustCompile("^ParsePort$"),
+		Warnf: func(format string, args ...any) {
+			warnings = append(warnings, fmt.Sprintf(format, args...))
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, changed, 1)
+	for _, src := range changed {
+		code := string(src)
+		assert.Contains(t, code, "func ParsePort(s string) (res result.Result[int]) {")
+		assert.Contains(t, code, "func LoadConfig(name string) (*Config, error) {")
+		assert.Contains(t, code, "port, err := ParsePort(string(data)).UnwrapWithError()")
+	}
+}
+
+func TestLoadError(t *testing.T) {
+	_, err := Migrate(Config{Patterns: []string{"./testdata/missing"}})
+	assert.ErrorIs(t, err, ErrLoad)
+}
+
+func position(t *testing.T, name string, line, column int) string {
+	path, err := filepath.Abs(name)
+	require.NoError(t, err)
+	return fmt.Sprintf("%s:%d:%d", path, line, column)
+}
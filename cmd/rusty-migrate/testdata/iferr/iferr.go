@@ -0,0 +1,81 @@
+package iferr
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+type Config struct {
+	Name string
+	Port int
+}
+
+func ParsePort(s string) (int, error) {
+	port, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, err
+	}
+	if port <= 0 || port > 65535 {
+		return 0, fmt.Errorf("port %d is out of range", port)
+	}
+	return port, nil
+}
+
+func LoadConfig(name string) (*Config, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	port, err := ParsePort(string(data))
+	if err != nil {
+		return nil, err
+	}
+	return &Config{Name: name, Port: port}, nil
+}
+
+func Save(cfg *Config) error {
+	if cfg.Name == "" {
+		return errors.New("no name")
+	}
+	if err := os.WriteFile(cfg.Name, []byte(strconv.Itoa(cfg.Port)), 0o644); err != nil {
+		return err
+	}
+	return nil
+}
+
+func Passthrough(s string) (int, error) {
+	return ParsePort(s)
+}
+
+// The error is inspected, the function keeps the checks
+func Retry(name string) (*Config, error) {
+	cfg, err := LoadConfig(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{Name: name}, nil
+	}
+	return cfg, err
+}
+
+// Used as a value, the function is not rewritten
+func Callback(s string) (int, error) {
+	return strconv.Atoi(s)
+}
+
+var parsers = []func(string) (int, error){Callback}
+
+// Already has the name res
+func Named(s string) (int, error) {
+	res, err := strconv.Atoi(s)
+	return res * 2, err
+}
+
+func Describe(name string) string {
+	cfg, err := LoadConfig(name)
+	if err != nil {
+		return err.Error()
+	}
+	Save(cfg)
+	return fmt.Sprintf("%s:%d", cfg.Name, cfg.Port)
+}
@@ -0,0 +1,20 @@
+package use
+
+import "github.com/pakuula/go-rusty/cmd/rusty-migrate/testdata/iferr"
+
+func Ports(names []string) ([]int, error) {
+	var ports []int
+	for _, name := range names {
+		cfg, err := iferr.LoadConfig(name)
+		if err != nil {
+			return nil, err
+		}
+		ports = append(ports, cfg.Port)
+	}
+	return ports, nil
+}
+
+func Check(name string) bool {
+	_, err := iferr.LoadConfig(name)
+	return err == nil
+}
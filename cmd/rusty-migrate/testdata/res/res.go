@@ -0,0 +1,58 @@
+package res
+
+import (
+	"errors"
+	"os"
+	"strconv"
+
+	"github.com/pakuula/go-rusty/result"
+)
+
+type Config struct {
+	Name string
+	Port int
+}
+
+func ParsePort(s string) (res result.Result[int]) {
+	defer result.Catch(&res)
+	port := result.Must(strconv.Atoi(s))
+	if port <= 0 {
+		return result.Err[int](errors.New("negative port"))
+	}
+	return result.Val(port)
+}
+
+func LoadConfig(name string) (res result.Result[*Config]) {
+	defer result.Catch(&res)
+	data := result.Must(os.ReadFile(name))
+	port := ParsePort(string(data)).Must()
+	return result.Val(&Config{Name: name, Port: port})
+}
+
+func Save(cfg *Config) (res result.ResultVoid) {
+	defer result.Catch(&res)
+	ParsePort(strconv.Itoa(cfg.Port)).Must()
+	result.NoError(os.WriteFile(cfg.Name, []byte(strconv.Itoa(cfg.Port)), 0o644))
+	return result.Void(nil)
+}
+
+func Passthrough(s string) result.Result[int] {
+	return ParsePort(s)
+}
+
+func Workdir() result.Result[string] {
+	return result.Wrap(os.Getwd())
+}
+
+// Must is a part of an expression, the function is not rewritten
+func Double(s string) (res result.Result[int]) {
+	defer result.Catch(&res)
+	return result.Val(2 * ParsePort(s).Must())
+}
+
+func Describe(name string) string {
+	if LoadConfig(name).IsError() {
+		return "broken"
+	}
+	return LoadConfig(name).UnwrapOrDefault().Name
+}
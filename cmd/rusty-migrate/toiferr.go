@@ -0,0 +1,342 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// A statement with Must that becomes an error check
+type mustStmt struct {
+	stmt ast.Stmt
+	lhs  ast.Expr // nil for expression statements
+	must *ast.CallExpr
+	name string // Must, NoError or Result.Must
+	// The function returns the error only
+	errorOnly bool
+}
+
+// Result[T] functions become (T, error) functions
+func (self *migration) toIfErr() error {
+	catches := map[*function]ast.Stmt{}
+	self.funcDecls(func(pkg *packages.Package, file *file, decl *ast.FuncDecl) {
+		results := decl.Type.Results
+		if results == nil || len(results.List) != 1 || len(results.List[0].Names) > 1 {
+			return
+		}
+		field := results.List[0]
+		value, ok := resultValue(pkg.TypesInfo.TypeOf(field.Type))
+		if !ok {
+			return
+		}
+		fn := &function{pkg: pkg, file: file, decl: decl, value: value}
+		if value != nil {
+			fn.valueText = types.TypeString(value, file.qualifier(pkg.Types))
+			if index, ok := field.Type.(*ast.IndexExpr); ok {
+				fn.valueText = string(file.src[file.tf.Offset(index.Index.Pos()):file.tf.Offset(index.Index.End())])
+			}
+		}
+		key := pkg.PkgPath + "." + decl.Name.Name
+		if len(field.Names) == 1 {
+			catch := self.catchOf(pkg.TypesInfo, decl, pkg.TypesInfo.Defs[field.Names[0]])
+			if catch == nil {
+				self.Warnf("%s: skipped, the named result is used other than by defer result.Catch", key)
+				return
+			}
+			catches[fn] = catch
+		}
+		self.converted[key] = fn
+	})
+	self.onlyCalled()
+
+	for key, fn := range self.converted {
+		if _, ok := self.mustStmts(fn, catches[fn] != nil); !ok {
+			delete(self.converted, key)
+		}
+	}
+	// The calls of the converted functions are known now
+	musts := map[*function][]mustStmt{}
+	for _, fn := range self.converted {
+		musts[fn], _ = self.mustStmts(fn, true)
+		for _, m := range musts[fn] {
+			if m.name == "Result.Must" {
+				if inner, converted := self.callee(fn.pkg.TypesInfo, m.recv()); converted != nil {
+					self.handled[inner] = true
+				}
+			}
+		}
+	}
+	returns := map[*function][]*ast.ReturnStmt{}
+	for _, fn := range self.converted {
+		returns[fn] = self.resultReturns(fn)
+	}
+	self.reverseCalls()
+	for _, fn := range self.converted {
+		self.rewriteToIfErr(fn, catches[fn], musts[fn], returns[fn])
+	}
+	return nil
+}
+
+// Returns T of Result[T], nil for ResultVoid
+func resultValue(t types.Type) (types.Type, bool) {
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil || named.Obj().Pkg().Path() != resultPath || named.Obj().Name() != "Result" {
+		return nil, false
+	}
+	value := named.TypeArgs().At(0)
+	if void, ok := value.(*types.Named); ok && void.Obj().Pkg() == named.Obj().Pkg() && void.Obj().Name() == "_Void" {
+		return nil, true
+	}
+	return value, true
+}
+
+// Returns the statement `defer result.Catch(&res)` if it is the first statement
+// and the only use of the named result
+func (self *migration) catchOf(info *types.Info, decl *ast.FuncDecl, res types.Object) ast.Stmt {
+	if len(decl.Body.List) == 0 {
+		return nil
+	}
+	stmt, ok := decl.Body.List[0].(*ast.DeferStmt)
+	if !ok {
+		return nil
+	}
+	if _, name := resultFunc(info, stmt.Call); name != "Catch" || len(stmt.Call.Args) != 1 {
+		return nil
+	}
+	arg, ok := stmt.Call.Args[0].(*ast.UnaryExpr)
+	if !ok || arg.Op != token.AND {
+		return nil
+	}
+	if id, ok := arg.X.(*ast.Ident); !ok || info.Uses[id] != res {
+		return nil
+	}
+	uses := 0
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && info.Uses[id] == res {
+			uses++
+		}
+		if ret, ok := n.(*ast.ReturnStmt); ok && len(ret.Results) == 0 {
+			// A naked return of the named result
+			uses++
+		}
+		return true
+	})
+	if uses > 1 {
+		return nil
+	}
+	return stmt
+}
+
+// Finds the statements with Must. The function is skipped if Must is called
+// in other places or there is no deferred Catch.
+func (self *migration) mustStmts(fn *function, caught bool) ([]mustStmt, bool) {
+	info := fn.pkg.TypesInfo
+	key := fn.pkg.PkgPath + "." + fn.decl.Name.Name
+	found := map[*ast.CallExpr]bool{}
+	var stmts []mustStmt
+	stmtLists(fn.decl.Body, func(list []ast.Stmt) {
+		for _, stmt := range list {
+			var lhs, e ast.Expr
+			switch s := stmt.(type) {
+			case *ast.ExprStmt:
+				e = s.X
+			case *ast.AssignStmt:
+				if s.Tok != token.DEFINE || len(s.Lhs) != 1 || len(s.Rhs) != 1 {
+					continue
+				}
+				lhs, e = s.Lhs[0], s.Rhs[0]
+			default:
+				continue
+			}
+			if m, ok := mustCall(info, e); ok && (lhs == nil || !m.errorOnly) {
+				m.stmt, m.lhs = stmt, lhs
+				stmts = append(stmts, m)
+				found[m.must] = true
+			}
+		}
+	})
+
+	ok := true
+	ast.Inspect(fn.decl.Body, func(n ast.Node) bool {
+		call, isCall := n.(*ast.CallExpr)
+		if !ok || !isCall || found[call] {
+			return ok
+		}
+		switch _, name := resultFunc(info, call); name {
+		case "Must", "NoError", "Result.Must", "Result.Mustf":
+			self.Warnf("%s: skipped, %s at %s is not a statement", key, name, self.fset.Position(call.Pos()))
+			ok = false
+		}
+		return true
+	})
+	switch {
+	case !ok || len(stmts) == 0:
+	case !caught:
+		self.Warnf("%s: skipped, Must without defer result.Catch", key)
+		ok = false
+	case mentions(fn.decl, "err"):
+		self.Warnf("%s: skipped, the name err is taken", key)
+		ok = false
+	}
+	return stmts, ok
+}
+
+// Matches the calls of Must, NoError and Result.Must
+func mustCall(info *types.Info, e ast.Expr) (mustStmt, bool) {
+	call, name := resultFunc(info, e)
+	m := mustStmt{must: call, name: name}
+	switch name {
+	case "Must":
+	case "NoError":
+		m.errorOnly = true
+	case "Result.Must":
+		value, _ := resultValue(info.TypeOf(m.recv()))
+		m.errorOnly = value == nil
+	default:
+		return m, false
+	}
+	return m, true
+}
+
+func (self *mustStmt) recv() ast.Expr { return self.must.Fun.(*ast.SelectorExpr).X }
+
+// Returns the expression of type (T, error) or error Must is applied to
+func (self *migration) mustSource(fn *function, m mustStmt) string {
+	file := fn.file
+	if m.name != "Result.Must" {
+		return file.render(m.must.Lparen+1, m.must.Rparen)
+	}
+	source := file.text(m.recv())
+	if _, converted := self.callee(fn.pkg.TypesInfo, m.recv()); converted != nil {
+		return source
+	}
+	if m.errorOnly {
+		return source + ".Err()"
+	}
+	return source + ".UnwrapWithError()"
+}
+
+// Returns the return statements, the returned converted calls are kept as they are
+func (self *migration) resultReturns(fn *function) []*ast.ReturnStmt {
+	var found []*ast.ReturnStmt
+	inspectBody(fn.decl.Body, func(n ast.Node) {
+		if ret, ok := n.(*ast.ReturnStmt); ok && len(ret.Results) == 1 {
+			if call, converted := self.callee(fn.pkg.TypesInfo, ret.Results[0]); converted != nil {
+				self.handled[call] = true
+			} else {
+				found = append(found, ret)
+			}
+		}
+	})
+	return found
+}
+
+// Wraps the results of the converted functions into Result at the calls that are not rewritten otherwise
+func (self *migration) reverseCalls() {
+	for _, pkg := range self.pkgs {
+		for _, syntax := range pkg.Syntax {
+			file := self.file(pkg, syntax)
+			statements := map[*ast.CallExpr]bool{}
+			ast.Inspect(syntax, func(n ast.Node) bool {
+				switch n := n.(type) {
+				case *ast.ExprStmt:
+					if call, ok := ast.Unparen(n.X).(*ast.CallExpr); ok {
+						statements[call] = true
+					}
+				case *ast.GoStmt:
+					statements[n.Call] = true
+				case *ast.DeferStmt:
+					statements[n.Call] = true
+				case *ast.CallExpr:
+					if inner := self.unwrapped(pkg.TypesInfo, n); inner != nil {
+						// F().UnwrapWithError() becomes F()
+						file.replace(inner.End(), n.End(), "")
+						self.handled[inner] = true
+						break
+					}
+					_, fn := self.callee(pkg.TypesInfo, n)
+					if fn == nil || self.handled[n] || statements[n] {
+						break
+					}
+					if fn.void() {
+						file.replace(n.Pos(), n.Pos(), file.result()+".Void(")
+					} else {
+						file.replace(n.Pos(), n.Pos(), file.result()+".Wrap(")
+					}
+					file.replace(n.End(), n.End(), ")")
+				}
+				return true
+			})
+		}
+	}
+}
+
+// Returns the converted call of F().UnwrapWithError() or F().Err()
+func (self *migration) unwrapped(info *types.Info, call *ast.CallExpr) *ast.CallExpr {
+	_, name := resultFunc(info, call)
+	if name != "Result.UnwrapWithError" && name != "Result.Err" {
+		return nil
+	}
+	inner, fn := self.callee(info, call.Fun.(*ast.SelectorExpr).X)
+	if fn == nil || self.handled[inner] || fn.void() != (name == "Result.Err") {
+		return nil
+	}
+	return inner
+}
+
+func (self *migration) rewriteToIfErr(fn *function, catch ast.Stmt, musts []mustStmt, returns []*ast.ReturnStmt) {
+	file, info := fn.file, fn.pkg.TypesInfo
+	failure := "return err"
+	if !fn.void() {
+		failure = fmt.Sprintf("return %s, err", zeroValue(fn.value, file.qualifier(fn.pkg.Types)))
+	}
+
+	for _, m := range musts {
+		source := self.mustSource(fn, m)
+		var text string
+		switch {
+		case m.lhs != nil:
+			text = fmt.Sprintf("%s, err := %s\nif err != nil {\n%s\n}", file.text(m.lhs), source, failure)
+		case m.errorOnly:
+			text = fmt.Sprintf("if err := %s; err != nil {\n%s\n}", source, failure)
+		default:
+			text = fmt.Sprintf("if _, err := %s; err != nil {\n%s\n}", source, failure)
+		}
+		file.replace(m.stmt.Pos(), m.stmt.End(), text)
+	}
+
+	for _, ret := range returns {
+		e := ret.Results[0]
+		call, name := resultFunc(info, e)
+		var text string
+		switch {
+		case name == "Val" && len(call.Args) == 1:
+			text = file.text(call.Args[0]) + ", nil"
+		case name == "Err" && len(call.Args) == 1 && !fn.void():
+			text = fmt.Sprintf("%s, %s", zeroValue(fn.value, file.qualifier(fn.pkg.Types)), file.text(call.Args[0]))
+		case name == "Wrap" || name == "Void":
+			text = file.render(call.Lparen+1, call.Rparen)
+		case fn.void():
+			text = file.text(e) + ".Err()"
+		default:
+			text = file.text(e) + ".UnwrapWithError()"
+		}
+		file.replace(e.Pos(), e.End(), text)
+	}
+
+	if catch != nil {
+		file.remove(catch)
+	}
+	typ := "error"
+	if !fn.void() {
+		typ = fmt.Sprintf("(%s, error)", fn.valueText)
+	}
+	file.replace(fn.decl.Type.Results.Pos(), fn.decl.Type.Results.End(), typ)
+}
@@ -0,0 +1,371 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// An error check that becomes Must:
+//
+//	x, err := f()
+//	if err != nil {
+//		return zero, err
+//	}
+type check struct {
+	start, end token.Pos
+	err        types.Object
+	lhs        ast.Expr // nil for error-only calls
+	define     bool     // declares lhs
+	call       *ast.CallExpr
+}
+
+// (T, error) functions become Result[T] functions
+func (self *migration) toResult() error {
+	errType := types.Universe.Lookup("error").Type()
+	self.funcDecls(func(pkg *packages.Package, file *file, decl *ast.FuncDecl) {
+		results := decl.Type.Results
+		if results == nil || len(results.List) > 2 {
+			return
+		}
+		var typs []ast.Expr
+		for _, field := range results.List {
+			if len(field.Names) > 0 {
+				return
+			}
+			typs = append(typs, field.Type)
+		}
+		if len(typs) == 0 || !types.Identical(pkg.TypesInfo.TypeOf(typs[len(typs)-1]), errType) {
+			return
+		}
+		fn := &function{pkg: pkg, file: file, decl: decl}
+		if len(typs) == 2 {
+			fn.value = pkg.TypesInfo.TypeOf(typs[0])
+			fn.valueText = string(file.src[file.tf.Offset(typs[0].Pos()):file.tf.Offset(typs[0].End())])
+		}
+		key := pkg.PkgPath + "." + decl.Name.Name
+		if mentions(decl, "res") {
+			self.Warnf("%s: skipped, the name res is taken", key)
+			return
+		}
+		self.converted[key] = fn
+	})
+	self.onlyCalled()
+
+	checks := map[*function][]check{}
+	returns := map[*function][]*ast.ReturnStmt{}
+	for _, fn := range self.converted {
+		checks[fn] = self.errorChecks(fn)
+		returns[fn] = self.returns(fn, checks[fn])
+	}
+	self.forwardCalls()
+	for _, fn := range self.converted {
+		self.rewriteToResult(fn, checks[fn], returns[fn])
+	}
+	return nil
+}
+
+// Finds the error checks that can be replaced. An error variable is eliminated
+// only if all its uses are in the checks.
+func (self *migration) errorChecks(fn *function) []check {
+	info := fn.pkg.TypesInfo
+	var found []check
+	stmtLists(fn.decl.Body, func(list []ast.Stmt) {
+		for i, stmt := range list {
+			if c, ok := self.checkWithInit(fn, stmt); ok {
+				found = append(found, c)
+			} else if i+1 < len(list) {
+				if c, ok := self.checkPair(fn, stmt, list[i+1]); ok {
+					found = append(found, c)
+				}
+			}
+		}
+	})
+
+	uses := map[types.Object]int{}
+	inspectBody(fn.decl.Body, func(n ast.Node) {
+		if id, ok := n.(*ast.Ident); ok {
+			if obj := info.ObjectOf(id); obj != nil {
+				uses[obj]++
+			}
+		}
+	})
+	covered := map[types.Object]int{}
+	for _, c := range found {
+		covered[c.err] += 3
+	}
+	var checks []check
+	for _, c := range found {
+		if covered[c.err] == uses[c.err] {
+			checks = append(checks, c)
+			self.handled[c.call] = self.handled[c.call] || self.isConverted(info, c.call)
+		}
+	}
+	return checks
+}
+
+func (self *migration) isConverted(info *types.Info, call *ast.CallExpr) bool {
+	_, fn := self.callee(info, call)
+	return fn != nil
+}
+
+// Matches the statement pair `x, err := f()` and `if err != nil { return ..., err }`
+func (self *migration) checkPair(fn *function, first, second ast.Stmt) (check, bool) {
+	assign, ok := first.(*ast.AssignStmt)
+	if !ok || len(assign.Rhs) != 1 || len(assign.Lhs) > 2 || (assign.Tok != token.DEFINE && assign.Tok != token.ASSIGN) {
+		return check{}, false
+	}
+	call, ok := ast.Unparen(assign.Rhs[0]).(*ast.CallExpr)
+	if !ok {
+		return check{}, false
+	}
+	ifStmt, ok := second.(*ast.IfStmt)
+	if !ok || ifStmt.Init != nil {
+		return check{}, false
+	}
+	errObj := self.errReturned(fn, ifStmt)
+	errId, ok := assign.Lhs[len(assign.Lhs)-1].(*ast.Ident)
+	if errObj == nil || !ok || fn.pkg.TypesInfo.ObjectOf(errId) != errObj {
+		return check{}, false
+	}
+	c := check{start: first.Pos(), end: second.End(), err: errObj, call: call, define: assign.Tok == token.DEFINE}
+	if len(assign.Lhs) == 2 {
+		c.lhs = assign.Lhs[0]
+	}
+	return c, true
+}
+
+// Matches `if err := f(); err != nil { return ..., err }`
+func (self *migration) checkWithInit(fn *function, stmt ast.Stmt) (check, bool) {
+	ifStmt, ok := stmt.(*ast.IfStmt)
+	if !ok || ifStmt.Init == nil {
+		return check{}, false
+	}
+	assign, ok := ifStmt.Init.(*ast.AssignStmt)
+	if !ok || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+		return check{}, false
+	}
+	call, ok := ast.Unparen(assign.Rhs[0]).(*ast.CallExpr)
+	errObj := self.errReturned(fn, ifStmt)
+	errId, isIdent := assign.Lhs[0].(*ast.Ident)
+	if !ok || errObj == nil || !isIdent || fn.pkg.TypesInfo.ObjectOf(errId) != errObj {
+		return check{}, false
+	}
+	return check{start: stmt.Pos(), end: stmt.End(), err: errObj, call: call}, true
+}
+
+// Returns the error variable of `if err != nil { return ..., err }`
+func (self *migration) errReturned(fn *function, ifStmt *ast.IfStmt) types.Object {
+	info := fn.pkg.TypesInfo
+	cond, ok := ifStmt.Cond.(*ast.BinaryExpr)
+	if !ok || cond.Op != token.NEQ || ifStmt.Else != nil || len(ifStmt.Body.List) != 1 {
+		return nil
+	}
+	errId, ok := cond.X.(*ast.Ident)
+	if !ok || !isNil(info, cond.Y) {
+		return nil
+	}
+	obj := info.ObjectOf(errId)
+	ret, ok := ifStmt.Body.List[0].(*ast.ReturnStmt)
+	if !ok || len(ret.Results) == 0 {
+		return nil
+	}
+	if last, ok := ret.Results[len(ret.Results)-1].(*ast.Ident); !ok || info.ObjectOf(last) != obj {
+		return nil
+	}
+	// The other results are dropped, they must have no side effects
+	for _, e := range ret.Results[:len(ret.Results)-1] {
+		switch e := ast.Unparen(e).(type) {
+		case *ast.Ident, *ast.BasicLit:
+		case *ast.CompositeLit:
+			if len(e.Elts) > 0 {
+				return nil
+			}
+		default:
+			return nil
+		}
+	}
+	return obj
+}
+
+func isNil(info *types.Info, e ast.Expr) bool {
+	tv, ok := info.Types[e]
+	return ok && tv.IsNil()
+}
+
+// Returns the return statements outside of the checks
+func (self *migration) returns(fn *function, checks []check) []*ast.ReturnStmt {
+	var found []*ast.ReturnStmt
+	inspectBody(fn.decl.Body, func(n ast.Node) {
+		ret, ok := n.(*ast.ReturnStmt)
+		if !ok {
+			return
+		}
+		for _, c := range checks {
+			if c.start <= ret.Pos() && ret.End() <= c.end {
+				return
+			}
+		}
+		if len(ret.Results) == 1 {
+			if call, ok := ast.Unparen(ret.Results[0]).(*ast.CallExpr); ok && self.isConverted(fn.pkg.TypesInfo, call) {
+				// Returns the Result as it is
+				self.handled[call] = true
+			}
+		}
+		found = append(found, ret)
+	})
+	return found
+}
+
+// Unwraps the results of the converted functions at the calls that are not rewritten otherwise
+func (self *migration) forwardCalls() {
+	for _, pkg := range self.pkgs {
+		for _, syntax := range pkg.Syntax {
+			file := self.file(pkg, syntax)
+			statements := map[*ast.CallExpr]bool{}
+			ast.Inspect(syntax, func(n ast.Node) bool {
+				switch n := n.(type) {
+				case *ast.ExprStmt:
+					if call, ok := ast.Unparen(n.X).(*ast.CallExpr); ok {
+						statements[call] = true
+					}
+				case *ast.GoStmt:
+					statements[n.Call] = true
+				case *ast.DeferStmt:
+					statements[n.Call] = true
+				case *ast.CallExpr:
+					if inner := self.wrapped(pkg.TypesInfo, n); inner != nil {
+						// result.Wrap(F()) becomes F()
+						file.replace(n.Pos(), inner.Pos(), "")
+						file.replace(inner.End(), n.End(), "")
+						self.handled[inner] = true
+						break
+					}
+					_, fn := self.callee(pkg.TypesInfo, n)
+					if fn == nil || self.handled[n] || statements[n] {
+						break
+					}
+					if fn.void() {
+						file.replace(n.End(), n.End(), ".Err()")
+					} else {
+						file.replace(n.End(), n.End(), ".UnwrapWithError()")
+					}
+				}
+				return true
+			})
+		}
+	}
+}
+
+// Returns the converted call of result.Wrap(F()) or result.Void(F())
+func (self *migration) wrapped(info *types.Info, call *ast.CallExpr) *ast.CallExpr {
+	_, name := resultFunc(info, call)
+	if (name != "Wrap" && name != "Void") || len(call.Args) != 1 {
+		return nil
+	}
+	inner, fn := self.callee(info, call.Args[0])
+	if fn == nil || self.handled[inner] || fn.void() != (name == "Void") {
+		return nil
+	}
+	return inner
+}
+
+func (self *migration) rewriteToResult(fn *function, checks []check, returns []*ast.ReturnStmt) {
+	file, info := fn.file, fn.pkg.TypesInfo
+	pkg := file.result()
+
+	for _, c := range checks {
+		var expr string
+		switch {
+		case self.isConverted(info, c.call):
+			expr = file.text(c.call) + ".Must()"
+		case c.lhs == nil:
+			expr = fmt.Sprintf("%s.NoError(%s)", pkg, file.text(c.call))
+		default:
+			expr = fmt.Sprintf("%s.Must(%s)", pkg, file.text(c.call))
+		}
+		stmt := expr
+		if id, ok := c.lhs.(*ast.Ident); c.lhs != nil && (!ok || id.Name != "_") {
+			op := "="
+			if c.define && ok && info.Defs[id] != nil {
+				op = ":="
+			}
+			stmt = fmt.Sprintf("%s %s %s", file.text(c.lhs), op, expr)
+		}
+		file.replace(c.start, c.end, stmt)
+	}
+
+	for _, ret := range returns {
+		if text, ok := self.resultReturn(fn, ret, pkg); ok {
+			file.replace(ret.Pos(), ret.End(), text)
+		}
+	}
+
+	typ := pkg + ".ResultVoid"
+	if !fn.void() {
+		typ = fmt.Sprintf("%s.Result[%s]", pkg, fn.valueText)
+	}
+	if len(checks) > 0 {
+		typ = "(res " + typ + ")"
+		file.replace(fn.decl.Body.Lbrace+1, fn.decl.Body.Lbrace+1, fmt.Sprintf("\ndefer %s.Catch(&res)", pkg))
+	}
+	file.replace(fn.decl.Type.Results.Pos(), fn.decl.Type.Results.End(), typ)
+}
+
+// Returns the return statement of the Result function
+func (self *migration) resultReturn(fn *function, ret *ast.ReturnStmt, pkg string) (string, bool) {
+	file, info := fn.file, fn.pkg.TypesInfo
+	if len(ret.Results) == 1 {
+		e := ret.Results[0]
+		if call, ok := ast.Unparen(e).(*ast.CallExpr); ok && self.handled[call] {
+			return "", false
+		}
+		switch {
+		case fn.void() && isNil(info, e):
+			return fmt.Sprintf("return %s.Void(nil)", pkg), true
+		case fn.void():
+			return fmt.Sprintf("return %s.Void(%s)", pkg, file.text(e)), true
+		default:
+			return fmt.Sprintf("return %s.Wrap(%s)", pkg, file.text(e)), true
+		}
+	}
+	val, err := ret.Results[0], ret.Results[1]
+	// Type arguments are explicit unless the type of the value is T
+	typeArg := ""
+	if tv, ok := info.Types[val]; !ok || tv.Value != nil || !types.Identical(tv.Type, fn.value) {
+		typeArg = "[" + fn.valueText + "]"
+	}
+	switch {
+	case isNil(info, err):
+		return fmt.Sprintf("return %s.Val%s(%s)", pkg, typeArg, file.text(val)), true
+	case isNewError(info, err):
+		return fmt.Sprintf("return %s.Err[%s](%s)", pkg, fn.valueText, file.text(err)), true
+	default:
+		return fmt.Sprintf("return %s.Wrap%s(%s, %s)", pkg, typeArg, file.text(val), file.text(err)), true
+	}
+}
+
+// Reports if the expression creates an error, e.g. calls errors.New or fmt.Errorf
+func isNewError(info *types.Info, e ast.Expr) bool {
+	call, ok := ast.Unparen(e).(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	id := calleeIdent(call)
+	if id == nil {
+		return false
+	}
+	fn, ok := info.Uses[id].(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return false
+	}
+	name := fn.Pkg().Path() + "." + fn.Name()
+	return name == "errors.New" || name == "fmt.Errorf"
+}
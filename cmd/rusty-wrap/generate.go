@@ -0,0 +1,431 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"go/types"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/tools/go/packages"
+)
+
+const (
+	resultPath = "github.com/pakuula/go-rusty/result"
+	optionPath = "github.com/pakuula/go-rusty/option"
+	tuplePath  = "github.com/pakuula/go-rusty/tuple"
+)
+
+var ErrNotFound = errors.New("not found")
+
+// Settings of the generator
+type Config struct {
+	// Import path or directory of the wrapped package
+	Pkg string
+	// Package name of the generated file, <pkg>_r by default
+	Name string
+	// Functions and Type.Method to wrap, all if empty. Type.* matches all methods of Type.
+	Allow map[string]bool
+	// Functions and Type.Method to skip
+	Deny map[string]bool
+	// Named types whose methods are wrapped
+	Types []string
+	// Renames of functions and Type.Method
+	Rename map[string]string
+	// Build constraint of the generated file
+	Tags string
+	// Command line arguments recorded in the header
+	Args string
+	// Reports skipped functions, may be nil
+	Warnf func(format string, args ...any)
+}
+
+// Methods whose signatures are checked by go vet. Their wrappers
+// can't keep the name unless the signature is unchanged.
+var stdMethods = map[string]bool{
+	"Format": true, "GobDecode": true, "GobEncode": true, "MarshalJSON": true,
+	"MarshalXML": true, "Peek": true, "ReadByte": true, "ReadFrom": true,
+	"ReadRune": true, "Scan": true, "Seek": true, "UnmarshalJSON": true,
+	"UnmarshalXML": true, "UnreadByte": true, "UnreadRune": true,
+	"WriteByte": true, "WriteTo": true,
+}
+
+// Loads the package and returns the formatted source of the wrappers
+func Generate(cfg Config) ([]byte, error) {
+	pkgs, err := packages.Load(&packages.Config{Mode: packages.NeedName | packages.NeedTypes}, cfg.Pkg)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("%s: expected one package, got %d", cfg.Pkg, len(pkgs))
+	}
+	if len(pkgs[0].Errors) > 0 {
+		return nil, fmt.Errorf("%s: %v", cfg.Pkg, pkgs[0].Errors[0])
+	}
+	if cfg.Name == "" {
+		cfg.Name = pkgs[0].Name + "_r"
+	}
+	if cfg.Warnf == nil {
+		cfg.Warnf = func(string, ...any) {}
+	}
+
+	g := &generator{Config: cfg, pkg: pkgs[0].Types, imports: map[string]string{}}
+	g.use(g.pkg)
+	scope := g.pkg.Scope()
+	for _, name := range scope.Names() {
+		if fn, ok := scope.Lookup(name).(*types.Func); ok && fn.Exported() && g.allowed(name) {
+			g.function(fn)
+		}
+	}
+	for _, name := range cfg.Types {
+		obj, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok {
+			return nil, fmt.Errorf("type %s.%s: %w", g.pkg.Path(), name, ErrNotFound)
+		}
+		if err := g.named(obj); err != nil {
+			return nil, err
+		}
+	}
+	return g.source()
+}
+
+type generator struct {
+	Config
+	pkg     *types.Package
+	imports map[string]string // import path -> package name
+	body    bytes.Buffer
+}
+
+func (self *generator) allowed(key string) bool {
+	if self.Deny[key] {
+		return false
+	}
+	if len(self.Allow) == 0 || self.Allow[key] {
+		return true
+	}
+	typ, _, isMethod := strings.Cut(key, ".")
+	return isMethod && self.Allow[typ+".*"]
+}
+
+func (self *generator) rename(key, name string) string {
+	if to, ok := self.Rename[key]; ok {
+		return to
+	}
+	return name
+}
+
+// Returns the name of the package in the generated file
+func (self *generator) use(pkg *types.Package) string {
+	if name, ok := self.imports[pkg.Path()]; ok {
+		return name
+	}
+	name := pkg.Name()
+	taken := map[string]bool{}
+	for _, other := range self.imports {
+		taken[other] = true
+	}
+	for i := 2; taken[name]; i++ {
+		name = fmt.Sprintf("%s%d", pkg.Name(), i)
+	}
+	self.imports[pkg.Path()] = name
+	return name
+}
+
+func (self *generator) lib(importPath string) string {
+	return self.use(types.NewPackage(importPath, path.Base(importPath)))
+}
+
+func (self *generator) typeString(t types.Type) string {
+	return types.TypeString(t, self.use)
+}
+
+func (self *generator) function(fn *types.Func) {
+	sig := fn.Type().(*types.Signature)
+	name := self.rename(fn.Name(), fn.Name())
+	if !self.supported(fn, sig) {
+		return
+	}
+	w := self.wrap(sig, self.imports[self.pkg.Path()]+"."+fn.Name())
+	fmt.Fprintf(&self.body, "// %s wraps %s.%s.\n", name, self.pkg.Name(), fn.Name())
+	fmt.Fprintf(&self.body, "func %s(%s) %s {\n%s}\n\n", name, w.params, w.results, w.body)
+}
+
+func (self *generator) named(obj *types.TypeName) error {
+	named, ok := types.Unalias(obj.Type()).(*types.Named)
+	if !ok || named.TypeParams().Len() > 0 {
+		return fmt.Errorf("type %s.%s: not a non-generic named type", self.pkg.Path(), obj.Name())
+	}
+	isIface := types.IsInterface(named)
+	var wrapped types.Type = named
+	if !isIface {
+		wrapped = types.NewPointer(named)
+	}
+	typ := obj.Name()
+	field := fieldName(typ)
+	wrappedStr := self.typeString(wrapped)
+
+	fmt.Fprintf(&self.body, "// %s wraps %s so that its methods return results.\n", typ, wrappedStr)
+	fmt.Fprintf(&self.body, "type %s struct {\n%s %s\n}\n\n", typ, field, wrappedStr)
+	fmt.Fprintf(&self.body, "// Wrap%s wraps the value.\n", typ)
+	fmt.Fprintf(&self.body, "func Wrap%s(v %s) *%s {\nreturn &%s{%s: v}\n}\n\n", typ, wrappedStr, typ, typ, field)
+	fmt.Fprintf(&self.body, "// Unwrap returns the wrapped value.\n")
+	fmt.Fprintf(&self.body, "func (self *%s) Unwrap() %s {\nreturn self.%s\n}\n\n", typ, wrappedStr, field)
+
+	mset := types.NewMethodSet(wrapped)
+	methods := make([]*types.Func, 0, mset.Len())
+	for i := 0; i < mset.Len(); i++ {
+		if m := mset.At(i).Obj().(*types.Func); m.Exported() {
+			methods = append(methods, m)
+		}
+	}
+	sort.Slice(methods, func(i, j int) bool { return methods[i].Name() < methods[j].Name() })
+
+	for _, m := range methods {
+		key := typ + "." + m.Name()
+		if !self.allowed(key) {
+			continue
+		}
+		sig := m.Type().(*types.Signature)
+		if !self.supported(m, sig) {
+			continue
+		}
+		name := self.rename(key, m.Name())
+		// Checked before wrap, which imports the packages of the signature
+		if name == "Unwrap" {
+			self.Warnf("%s: skipped, clashes with the generated Unwrap; rename it", key)
+			continue
+		}
+		if stdMethods[name] && converted(sig.Results()) {
+			self.Warnf("%s: skipped, go vet reserves the name %s; rename it", key, name)
+			continue
+		}
+		w := self.wrap(sig, "self."+field+"."+m.Name())
+		fmt.Fprintf(&self.body, "// %s wraps (%s).%s.\n", name, wrappedStr, m.Name())
+		fmt.Fprintf(&self.body, "func (self *%s) %s(%s) %s {\n%s}\n\n", typ, name, w.params, w.results, w.body)
+	}
+	return nil
+}
+
+func fieldName(typ string) string {
+	r, size := utf8.DecodeRuneInString(typ)
+	name := string(unicode.ToLower(r)) + typ[size:]
+	if token.IsKeyword(name) {
+		return "v"
+	}
+	return name
+}
+
+// Reports if the wrapper of the function can be written outside of its package
+func (self *generator) supported(fn *types.Func, sig *types.Signature) bool {
+	if sig.TypeParams().Len() > 0 {
+		self.Warnf("%s: skipped, generic functions are not supported", fn.FullName())
+		return false
+	}
+	if !exportable(sig) {
+		self.Warnf("%s: skipped, the signature refers to unexported types", fn.FullName())
+		return false
+	}
+	return true
+}
+
+func exportable(t types.Type) bool {
+	switch t := t.(type) {
+	case *types.Alias:
+		if obj := t.Obj(); obj.Pkg() != nil && obj.Exported() && importable(obj.Pkg().Path()) {
+			return true
+		}
+		return exportable(types.Unalias(t))
+	case *types.Named:
+		obj := t.Obj()
+		if obj.Pkg() != nil && (!obj.Exported() || !importable(obj.Pkg().Path())) {
+			return false
+		}
+		for i := 0; i < t.TypeArgs().Len(); i++ {
+			if !exportable(t.TypeArgs().At(i)) {
+				return false
+			}
+		}
+	case *types.TypeParam:
+		return false
+	case *types.Pointer:
+		return exportable(t.Elem())
+	case *types.Slice:
+		return exportable(t.Elem())
+	case *types.Array:
+		return exportable(t.Elem())
+	case *types.Chan:
+		return exportable(t.Elem())
+	case *types.Map:
+		return exportable(t.Key()) && exportable(t.Elem())
+	case *types.Signature:
+		return exportable(t.Params()) && exportable(t.Results())
+	case *types.Tuple:
+		for i := 0; i < t.Len(); i++ {
+			if !exportable(t.At(i).Type()) {
+				return false
+			}
+		}
+	case *types.Struct:
+		for i := 0; i < t.NumFields(); i++ {
+			if !t.Field(i).Exported() || !exportable(t.Field(i).Type()) {
+				return false
+			}
+		}
+	case *types.Interface:
+		for i := 0; i < t.NumExplicitMethods(); i++ {
+			if !t.ExplicitMethod(i).Exported() || !exportable(t.ExplicitMethod(i).Type()) {
+				return false
+			}
+		}
+		for i := 0; i < t.NumEmbeddeds(); i++ {
+			if !exportable(t.EmbeddedType(i)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func importable(importPath string) bool {
+	for _, elem := range strings.Split(importPath, "/") {
+		if elem == "internal" || elem == "vendor" {
+			return false
+		}
+	}
+	return true
+}
+
+type wrapper struct {
+	params  string
+	results string
+	body    string
+}
+
+// Reports if wrap converts the results into Result or Option
+func converted(res *types.Tuple) bool {
+	n := res.Len()
+	last := func(t types.Type) bool { return n > 0 && types.Identical(res.At(n-1).Type(), t) }
+	errType := types.Universe.Lookup("error").Type()
+	return (n >= 1 && n <= 4 && last(errType)) || (n == 2 && last(types.Typ[types.Bool]))
+}
+
+func (self *generator) wrap(sig *types.Signature, fn string) wrapper {
+	var w wrapper
+
+	// Parameter names must not shadow the packages used in the body
+	taken := map[string]bool{"self": true, "err": true}
+	for _, name := range []string{self.imports[self.pkg.Path()], "result", "option", "tuple"} {
+		taken[name] = true
+	}
+	params, args := make([]string, 0, sig.Params().Len()), make([]string, 0, sig.Params().Len())
+	for i := 0; i < sig.Params().Len(); i++ {
+		p := sig.Params().At(i)
+		name := p.Name()
+		if name == "" || name == "_" {
+			name = fmt.Sprintf("p%d", i)
+		}
+		for taken[name] {
+			name += "_"
+		}
+		taken[name] = true
+		typ := self.typeString(p.Type())
+		arg := name
+		if sig.Variadic() && i == sig.Params().Len()-1 {
+			typ = "..." + self.typeString(p.Type().(*types.Slice).Elem())
+			arg += "..."
+		}
+		params = append(params, name+" "+typ)
+		args = append(args, arg)
+	}
+	w.params = strings.Join(params, ", ")
+	call := fmt.Sprintf("%s(%s)", fn, strings.Join(args, ", "))
+
+	res := sig.Results()
+	n := res.Len()
+	last := func(t types.Type) bool { return n > 0 && types.Identical(res.At(n-1).Type(), t) }
+	errType := types.Universe.Lookup("error").Type()
+	switch {
+	case n == 1 && last(errType):
+		w.results = self.lib(resultPath) + ".ResultVoid"
+		w.body = fmt.Sprintf("return %s.Void(%s)\n", self.lib(resultPath), call)
+	case n == 2 && last(errType):
+		w.results = fmt.Sprintf("%s.Result[%s]", self.lib(resultPath), self.typeString(res.At(0).Type()))
+		w.body = fmt.Sprintf("return %s.Wrap(%s)\n", self.lib(resultPath), call)
+	case n == 2 && last(types.Typ[types.Bool]):
+		w.results = fmt.Sprintf("%s.Option[%s]", self.lib(optionPath), self.typeString(res.At(0).Type()))
+		w.body = fmt.Sprintf("return %s.WrapOk(%s)\n", self.lib(optionPath), call)
+	case (n == 3 || n == 4) && last(errType):
+		tuple := map[int]string{3: "Pair", 4: "Triple"}[n]
+		typs, vars := make([]string, n-1), make([]string, n-1)
+		for i := range typs {
+			typs[i] = self.typeString(res.At(i).Type())
+			vars[i] = res.At(i).Name()
+			if vars[i] == "" || vars[i] == "_" || taken[vars[i]] {
+				vars[i] = fmt.Sprintf("v%d", i)
+			}
+			taken[vars[i]] = true
+		}
+		w.results = fmt.Sprintf("%s.Result[%s.%s[%s]]", self.lib(resultPath), self.lib(tuplePath), tuple, strings.Join(typs, ", "))
+		w.body = fmt.Sprintf("%s, err := %s\nreturn %s.Wrap(%s.New%s(%s), err)\n",
+			strings.Join(vars, ", "), call, self.lib(resultPath), self.lib(tuplePath), tuple, strings.Join(vars, ", "))
+	case n == 0:
+		w.body = call + "\n"
+	default:
+		typs := make([]string, n)
+		for i := range typs {
+			typs[i] = self.typeString(res.At(i).Type())
+		}
+		w.results = strings.Join(typs, ", ")
+		if n > 1 {
+			w.results = "(" + w.results + ")"
+		}
+		w.body = "return " + call + "\n"
+	}
+	return w
+}
+
+func (self *generator) source() ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by rusty-wrap %s; DO NOT EDIT.\n\n", self.Args)
+	if self.Tags != "" {
+		fmt.Fprintf(&buf, "//go:build %s\n\n", self.Tags)
+	}
+	fmt.Fprintf(&buf, "package %s\n\n", self.Name)
+
+	paths := make([]string, 0, len(self.imports))
+	for p := range self.imports {
+		paths = append(paths, p)
+	}
+	// Standard library first, then the rest
+	sort.Slice(paths, func(i, j int) bool {
+		iStd, jStd := !strings.Contains(paths[i], "."), !strings.Contains(paths[j], ".")
+		if iStd != jStd {
+			return iStd
+		}
+		return paths[i] < paths[j]
+	})
+	buf.WriteString("import (\n")
+	for i, p := range paths {
+		if i > 0 && strings.Contains(p, ".") && !strings.Contains(paths[i-1], ".") {
+			buf.WriteString("\n")
+		}
+		if name := self.imports[p]; name != path.Base(p) {
+			fmt.Fprintf(&buf, "%s %q\n", name, p)
+		} else {
+			fmt.Fprintf(&buf, "%q\n", p)
+		}
+	}
+	buf.WriteString(")\n\n")
+	buf.Write(self.body.Bytes())
+	return format.Source(buf.Bytes())
+}
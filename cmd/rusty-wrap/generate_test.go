@@ -0,0 +1,92 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/packages"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+const golden = "testdata/sample_r/sample_gen.go"
+
+func sampleConfig(warnings *[]string) Config {
+	return Config{
+		Pkg:    "./testdata/sample",
+		Deny:   map[string]bool{"Skipped": true},
+		Types:  []string{"Counter", "Source"},
+		Rename: map[string]string{"Plain": "Add"},
+		Args:   "-pkg ./testdata/sample",
+		Warnf: func(format string, args ...any) {
+			*warnings = append(*warnings, fmt.Sprintf(format, args...))
+		},
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	var warnings []string
+	src, err := Generate(sampleConfig(&warnings))
+	require.NoError(t, err)
+	if *update {
+		require.NoError(t, os.WriteFile(golden, src, 0o644))
+	}
+	expected, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(src))
+
+	assert.ElementsMatch(t, []string{
+		"github.com/pakuula/go-rusty/cmd/rusty-wrap/testdata/sample.Generic: skipped, generic functions are not supported",
+		"github.com/pakuula/go-rusty/cmd/rusty-wrap/testdata/sample.Hidden: skipped, the signature refers to unexported types",
+		"Counter.Seek: skipped, go vet reserves the name Seek; rename it",
+		"Counter.Unwrap: skipped, clashes with the generated Unwrap; rename it",
+		"Counter.WriteTo: skipped, go vet reserves the name WriteTo; rename it",
+	}, warnings)
+	// The skipped WriteTo does not import io
+	assert.NotContains(t, string(src), `"io"`)
+}
+
+func TestGeneratedCompiles(t *testing.T) {
+	pkgs, err := packages.Load(&packages.Config{Mode: packages.NeedTypes}, "./testdata/sample_r")
+	require.NoError(t, err)
+	require.Len(t, pkgs, 1)
+	assert.Empty(t, pkgs[0].Errors)
+}
+
+func TestAllow(t *testing.T) {
+	var warnings []string
+	cfg := sampleConfig(&warnings)
+	cfg.Allow = map[string]bool{"Parse": true, "Counter.*": true, "Source.Next": true}
+	cfg.Rename = map[string]string{"Counter.Seek": "SeekFrom", "Counter.Unwrap": "Err", "Counter.WriteTo": "WriteInto"}
+	cfg.Name = "wrapped"
+	cfg.Tags = "go1.21"
+	src, err := Generate(cfg)
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+
+	code := string(src)
+	assert.Contains(t, code, "//go:build go1.21\n\npackage wrapped\n")
+	assert.Contains(t, code, "func Parse(s string) result.Result[int] {")
+	assert.NotContains(t, code, "func Check(")
+	assert.Contains(t, code, "func (self *Counter) SeekFrom(offset int64, whence int) result.Result[int64] {")
+	assert.Contains(t, code, "func (self *Counter) Err() result.ResultVoid {")
+	assert.Contains(t, code, "func (self *Counter) WriteInto(w io.Writer) result.Result[int64] {")
+	assert.Contains(t, code, `"io"`)
+	assert.Contains(t, code, "func (self *Source) Next() option.Option[string] {")
+	assert.NotContains(t, code, "func (self *Source) Read(")
+}
+
+func TestUnknownType(t *testing.T) {
+	_, err := Generate(Config{Pkg: "./testdata/sample", Types: []string{"Missing"}})
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = Generate(Config{Pkg: "./testdata/sample", Types: []string{"ErrNegative"}})
+	assert.ErrorIs(t, err, ErrNotFound)
+}
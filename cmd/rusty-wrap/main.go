@@ -0,0 +1,102 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+// Command rusty-wrap generates a package of Result-returning wrappers
+// for the functions and methods of another package.
+//
+// Functions returning (T, error) become Result[T], error-only functions
+// become ResultVoid, (T, bool) functions become option.Option[T] and
+// (A, B, error) functions become Result[tuple.Pair[A, B]].
+// Other functions are forwarded unchanged.
+//
+// Usage:
+//
+//	rusty-wrap -pkg os -name os_r -deny Exit,Getpid -types File -o os_gen.go
+//
+// The tool is meant to be run by go generate:
+//
+//	//go:generate go run -C ../../cmd ./rusty-wrap -pkg io -name $GOPACKAGE -o ../result/io_r/io_gen.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	var (
+		cfg    Config
+		allow  string
+		deny   string
+		types  string
+		rename string
+		out    string
+	)
+	flag.StringVar(&cfg.Pkg, "pkg", "", "import path or directory of the package to wrap")
+	flag.StringVar(&cfg.Name, "name", "", "package name of the generated file (default <pkg>_r)")
+	flag.StringVar(&allow, "allow", "", "comma-separated functions and Type.Method to wrap, Type.* matches all methods")
+	flag.StringVar(&deny, "deny", "", "comma-separated functions and Type.Method to skip")
+	flag.StringVar(&types, "types", "", "comma-separated named types whose methods are wrapped")
+	flag.StringVar(&rename, "rename", "", "comma-separated Old=New renames of functions and Type.Method")
+	flag.StringVar(&cfg.Tags, "tags", "", "build constraint of the generated file")
+	flag.StringVar(&out, "o", "", "output file (default stdout)")
+	flag.Parse()
+
+	if cfg.Pkg == "" || flag.NArg() != 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	cfg.Allow = set(allow)
+	cfg.Deny = set(deny)
+	cfg.Types = list(types)
+	cfg.Rename = map[string]string{}
+	for _, item := range list(rename) {
+		from, to, ok := strings.Cut(item, "=")
+		if !ok {
+			fatalf("invalid rename %q", item)
+		}
+		cfg.Rename[from] = to
+	}
+	cfg.Args = strings.Join(os.Args[1:], " ")
+	cfg.Warnf = func(format string, args ...any) {
+		fmt.Fprintf(os.Stderr, "rusty-wrap: "+format+"\n", args...)
+	}
+
+	src, err := Generate(cfg)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	if out == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := os.WriteFile(out, src, 0o644); err != nil {
+		fatalf("%v", err)
+	}
+}
+
+func list(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func set(s string) map[string]bool {
+	items := map[string]bool{}
+	for _, item := range list(s) {
+		items[item] = true
+	}
+	return items
+}
+
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "rusty-wrap: "+format+"\n", args...)
+	os.Exit(1)
+}
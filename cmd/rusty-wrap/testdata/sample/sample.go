@@ -0,0 +1,49 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+// Package sample is wrapped by the tests of rusty-wrap
+package sample
+
+import (
+	"errors"
+	"io"
+	"strconv"
+)
+
+var ErrNegative = errors.New("negative")
+
+func Parse(s string) (int, error)                             { return strconv.Atoi(s) }
+func Check(n int) error                                       { return nil }
+func Lookup(m map[string]int, key string) (int, bool)         { v, ok := m[key]; return v, ok }
+func Split(s string, sep byte) (head, tail string, err error) { return s, "", nil }
+func Three() (int, string, bool, error)                       { return 0, "", false, nil }
+func Sum(result int, nums ...int) (int, error)                { return result, nil }
+func Unnamed(string, int) error                               { return nil }
+func Plain(a, b int) int                                      { return a + b }
+func Many() (int, int)                                        { return 0, 0 }
+func Nothing()                                                {}
+func Generic[T any](v T) (T, error)                           { return v, nil }
+func Hidden() (hidden, error)                                 { return hidden{}, nil }
+func Skipped() error                                          { return nil }
+
+type hidden struct{}
+
+type Counter struct{ n int }
+
+func (self *Counter) Add(n int) (int, error) {
+	if n < 0 {
+		return self.n, ErrNegative
+	}
+	self.n += n
+	return self.n, nil
+}
+func (self *Counter) Seek(offset int64, whence int) (int64, error) { return offset, nil }
+func (self *Counter) Unwrap() error                                { return nil }
+func (self *Counter) WriteTo(w io.Writer) (int64, error)           { return 0, nil }
+func (self Counter) Value() int                                    { return self.n }
+
+type Source interface {
+	io.Reader
+	Next() (string, bool)
+}
@@ -0,0 +1,112 @@
+// Code generated by rusty-wrap -pkg ./testdata/sample; DO NOT EDIT.
+
+package sample_r
+
+import (
+	"github.com/pakuula/go-rusty/cmd/rusty-wrap/testdata/sample"
+	"github.com/pakuula/go-rusty/option"
+	"github.com/pakuula/go-rusty/result"
+	"github.com/pakuula/go-rusty/tuple"
+)
+
+// Check wraps sample.Check.
+func Check(n int) result.ResultVoid {
+	return result.Void(sample.Check(n))
+}
+
+// Lookup wraps sample.Lookup.
+func Lookup(m map[string]int, key string) option.Option[int] {
+	return option.WrapOk(sample.Lookup(m, key))
+}
+
+// Many wraps sample.Many.
+func Many() (int, int) {
+	return sample.Many()
+}
+
+// Nothing wraps sample.Nothing.
+func Nothing() {
+	sample.Nothing()
+}
+
+// Parse wraps sample.Parse.
+func Parse(s string) result.Result[int] {
+	return result.Wrap(sample.Parse(s))
+}
+
+// Add wraps sample.Plain.
+func Add(a int, b int) int {
+	return sample.Plain(a, b)
+}
+
+// Split wraps sample.Split.
+func Split(s string, sep byte) result.Result[tuple.Pair[string, string]] {
+	head, tail, err := sample.Split(s, sep)
+	return result.Wrap(tuple.NewPair(head, tail), err)
+}
+
+// Sum wraps sample.Sum.
+func Sum(result_ int, nums ...int) result.Result[int] {
+	return result.Wrap(sample.Sum(result_, nums...))
+}
+
+// Three wraps sample.Three.
+func Three() result.Result[tuple.Triple[int, string, bool]] {
+	v0, v1, v2, err := sample.Three()
+	return result.Wrap(tuple.NewTriple(v0, v1, v2), err)
+}
+
+// Unnamed wraps sample.Unnamed.
+func Unnamed(p0 string, p1 int) result.ResultVoid {
+	return result.Void(sample.Unnamed(p0, p1))
+}
+
+// Counter wraps *sample.Counter so that its methods return results.
+type Counter struct {
+	counter *sample.Counter
+}
+
+// WrapCounter wraps the value.
+func WrapCounter(v *sample.Counter) *Counter {
+	return &Counter{counter: v}
+}
+
+// Unwrap returns the wrapped value.
+func (self *Counter) Unwrap() *sample.Counter {
+	return self.counter
+}
+
+// Add wraps (*sample.Counter).Add.
+func (self *Counter) Add(n int) result.Result[int] {
+	return result.Wrap(self.counter.Add(n))
+}
+
+// Value wraps (*sample.Counter).Value.
+func (self *Counter) Value() int {
+	return self.counter.Value()
+}
+
+// Source wraps sample.Source so that its methods return results.
+type Source struct {
+	source sample.Source
+}
+
+// WrapSource wraps the value.
+func WrapSource(v sample.Source) *Source {
+	return &Source{source: v}
+}
+
+// Unwrap returns the wrapped value.
+func (self *Source) Unwrap() sample.Source {
+	return self.source
+}
+
+// Next wraps (sample.Source).Next.
+func (self *Source) Next() option.Option[string] {
+	return option.WrapOk(self.source.Next())
+}
+
+// Read wraps (sample.Source).Read.
+func (self *Source) Read(p []byte) result.Result[int] {
+	return result.Wrap(self.source.Read(p))
+}
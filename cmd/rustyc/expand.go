@@ -0,0 +1,507 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// The ? in the type-checked source
+type tryCall struct {
+	*try
+	call   *ast.CallExpr
+	stmt   ast.Stmt // the statement the check is inserted before
+	sig    *types.Signature
+	root   bool   // the whole statement is the ?
+	value  string // the variable of the value
+	status string // the variable of the error or ok
+}
+
+// Expansion of the ? in a file
+type expansion struct {
+	*group
+	src    *source
+	syntax *ast.File
+	info   *types.Info
+	text   []byte
+	tf     *token.File
+	edits  []*edit
+	names  map[string]bool // the identifiers of the file
+	errs   map[int]error   // by line
+}
+
+// Replaces ? by the explicit checks and verifies the generated files
+func (self *group) expand(files map[*source]*ast.File, info *types.Info, out map[string][]byte) error {
+	var errs []error
+	generated := map[string][]byte{}
+	for _, src := range self.sources {
+		syntax := files[src]
+		tf := self.fset.File(syntax.Pos())
+		e := &expansion{group: self, src: src, syntax: syntax, info: info, tf: tf, names: map[string]bool{}, errs: map[int]error{}}
+		e.text = src.variant
+		text, err := e.run()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if text, err = self.finish(src, text); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		generated[outputName(src.name)] = text
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	if err := self.verify(generated); err != nil {
+		return err
+	}
+	for name, text := range generated {
+		out[name] = text
+	}
+	return nil
+}
+
+func (self *expansion) run() ([]byte, error) {
+	ast.Inspect(self.syntax, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			self.names[id.Name] = true
+		}
+		return true
+	})
+
+	// The ? by the statements
+	var tries []*tryCall
+	byID := map[int]*try{}
+	for _, t := range self.src.tries {
+		byID[t.id] = t
+	}
+	ast.Inspect(self.syntax, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		if id, ok := call.Fun.(*ast.Ident); ok && byID[markerID(id.Name)] != nil {
+			t := &tryCall{try: byID[markerID(id.Name)], call: call}
+			if self.locate(t) {
+				tries = append(tries, t)
+			}
+		}
+		return true
+	})
+
+	stmts := map[ast.Stmt][]*tryCall{}
+	var order []ast.Stmt
+	for _, t := range tries {
+		if stmts[t.stmt] == nil {
+			order = append(order, t.stmt)
+		}
+		stmts[t.stmt] = append(stmts[t.stmt], t)
+		self.nameVariables(t)
+		if !t.root {
+			self.replace(t.call.Pos(), t.call.End(), t.value)
+		}
+	}
+	// Inner statements first, e.g. in the function literals
+	sort.SliceStable(order, func(i, j int) bool {
+		if order[i].End() != order[j].End() {
+			return order[i].End() < order[j].End()
+		}
+		return order[i].Pos() > order[j].Pos()
+	})
+	for _, stmt := range order {
+		list := stmts[stmt]
+		// The operands are evaluated in the order of ?, the inner ones first
+		sort.SliceStable(list, func(i, j int) bool { return list[i].call.End() < list[j].call.End() })
+		self.expandStmt(stmt, list)
+	}
+	if len(self.errs) > 0 {
+		return nil, self.err()
+	}
+	text := self.renderOffsets(0, len(self.text))
+	for _, e := range self.edits {
+		if !e.consumed {
+			panic("rustyc: overlapping edits")
+		}
+	}
+	return []byte(text), nil
+}
+
+// Reports the line of the ?, the columns of the helper calls differ from the source
+func (self *expansion) errorf(pos token.Pos, format string, args ...any) {
+	line := self.tf.Line(pos)
+	err := fmt.Errorf("%s:%d: %w: %s", self.src.name, line, ErrTry, fmt.Sprintf(format, args...))
+	self.errs[line] = errors.Join(self.errs[line], err)
+}
+
+// Returns the errors ordered by line
+func (self *expansion) err() error {
+	lines := make([]int, 0, len(self.errs))
+	for line := range self.errs {
+		lines = append(lines, line)
+	}
+	sort.Ints(lines)
+	errs := make([]error, len(lines))
+	for i, line := range lines {
+		errs[i] = self.errs[line]
+	}
+	return errors.Join(errs...)
+}
+
+// Finds the statement and the function of the ?. Reports false if ? is not supported there.
+func (self *expansion) locate(t *tryCall) bool {
+	path, _ := astutil.PathEnclosingInterval(self.syntax, t.call.Pos(), t.call.End())
+	for i := 0; i+1 < len(path) && t.stmt == nil; i++ {
+		n := path[i]
+		switch p := path[i+1].(type) {
+		case *ast.BlockStmt:
+			t.stmt = n.(ast.Stmt)
+		case *ast.CaseClause:
+			if !contains(p.Body, n) {
+				self.errorf(t.call.Pos(), "? in a case expression")
+				return false
+			}
+			t.stmt = n.(ast.Stmt)
+		case *ast.CommClause:
+			if !contains(p.Body, n) {
+				self.errorf(t.call.Pos(), "? in a case expression")
+				return false
+			}
+			t.stmt = n.(ast.Stmt)
+		case *ast.BinaryExpr:
+			if (p.Op == token.LAND || p.Op == token.LOR) && n == p.Y {
+				self.errorf(t.call.Pos(), "? in the right operand of %s", p.Op)
+				return false
+			}
+		case *ast.IfStmt:
+			if n == p.Else {
+				self.errorf(t.call.Pos(), "? in the condition of else if")
+				return false
+			}
+			if n == p.Cond && p.Init != nil {
+				self.errorf(t.call.Pos(), "? in the condition of if with an init statement")
+				return false
+			}
+		case *ast.ForStmt:
+			if n == p.Cond || n == p.Post {
+				self.errorf(t.call.Pos(), "? in the condition or the post statement of for")
+				return false
+			}
+		case *ast.SwitchStmt:
+			if n == p.Tag && p.Init != nil {
+				self.errorf(t.call.Pos(), "? in the tag of switch with an init statement")
+				return false
+			}
+		case *ast.TypeSwitchStmt:
+			if n == p.Assign && p.Init != nil {
+				self.errorf(t.call.Pos(), "? in the type switch with an init statement")
+				return false
+			}
+		case *ast.DeferStmt, *ast.GoStmt:
+			if n == t.call {
+				self.errorf(t.call.Pos(), "? applied to a deferred call")
+				return false
+			}
+		case *ast.FuncDecl, *ast.FuncLit, *ast.File:
+			self.errorf(t.call.Pos(), "? outside of a function body")
+			return false
+		}
+		if t.stmt != nil {
+			for _, n := range path[i+1:] {
+				switch fn := n.(type) {
+				case *ast.FuncLit:
+					t.sig = self.info.TypeOf(fn).(*types.Signature)
+				case *ast.FuncDecl:
+					t.sig = self.info.Defs[fn.Name].Type().(*types.Signature)
+				}
+				if t.sig != nil {
+					break
+				}
+			}
+		}
+	}
+	if t.stmt == nil || t.sig == nil {
+		self.errorf(t.call.Pos(), "? outside of a function body")
+		return false
+	}
+	if stmt, ok := t.stmt.(*ast.ExprStmt); ok && ast.Unparen(stmt.X) == t.call {
+		t.root = true
+	}
+	if !t.root && (t.kind == errorOnly || t.kind == resultVoid) {
+		self.errorf(t.call.Pos(), "? on error or ResultVoid must be a statement")
+		return false
+	}
+	return true
+}
+
+func contains(list []ast.Stmt, n ast.Node) bool {
+	for _, stmt := range list {
+		if stmt == n {
+			return true
+		}
+	}
+	return false
+}
+
+// Picks the names of the variables that don't clash with the identifiers of the file
+func (self *expansion) nameVariables(t *tryCall) {
+	suffix := strconv.Itoa(t.id)
+	for self.names["try"+suffix] || self.names["err"+suffix] || self.names["ok"+suffix] {
+		suffix += "_"
+	}
+	t.value = "try" + suffix
+	t.status = "err" + suffix
+	if t.kind == option {
+		t.status = "ok" + suffix
+	}
+}
+
+// Inserts the checks before the statement
+func (self *expansion) expandStmt(stmt ast.Stmt, tries []*tryCall) {
+	var buf strings.Builder
+	start := self.tf.Offset(stmt.Pos())
+	lineStart := bytes.LastIndexByte(self.text[:start], '\n') + 1
+	indent := string(self.text[lineStart:start])
+	if strings.TrimSpace(indent) != "" {
+		// The statement follows other code on the line
+		buf.WriteString("\n")
+		indent = ""
+	} else {
+		start = lineStart
+	}
+	base := filepath.Base(self.src.name)
+	directive := func(pos token.Pos) {
+		fmt.Fprintf(&buf, "//line %s:%d:1\n%s", base, self.tf.Line(pos), indent)
+	}
+
+	for _, t := range tries {
+		x := t.call.Args[0]
+		failure, ok := self.failure(t)
+		if !ok {
+			continue
+		}
+		value := t.value
+		if t.root {
+			value = "_"
+		}
+		operand := self.render(x.Pos(), x.End())
+		directive(x.Pos())
+		switch t.kind {
+		case withError:
+			fmt.Fprintf(&buf, "%s, %s := %s\n", value, t.status, operand)
+		case errorOnly:
+			fmt.Fprintf(&buf, "%s := %s\n", t.status, operand)
+		case result:
+			if t.root {
+				fmt.Fprintf(&buf, "%s := %s.Err()\n", t.status, operand)
+			} else {
+				fmt.Fprintf(&buf, "%s, %s := %s.UnwrapWithError()\n", value, t.status, operand)
+			}
+		case resultVoid:
+			fmt.Fprintf(&buf, "%s := %s.Err()\n", t.status, operand)
+		case option:
+			fmt.Fprintf(&buf, "%s, %s := %s.UnwrapWithOk()\n", value, t.status, operand)
+		}
+		condition := t.status + " != nil"
+		if t.kind == option {
+			condition = "!" + t.status
+		}
+		directive(t.call.End())
+		fmt.Fprintf(&buf, "if %s {\n", condition)
+		directive(t.call.End())
+		fmt.Fprintf(&buf, "\treturn %s\n", failure)
+		directive(t.call.End())
+		buf.WriteString("}\n")
+	}
+
+	end := self.tf.Offset(stmt.End())
+	if tries[len(tries)-1].root {
+		// Nothing is left of the statement
+		if lineEnd := bytes.IndexByte(self.text[end:], '\n'); lineEnd >= 0 && len(bytes.TrimSpace(self.text[end:end+lineEnd])) == 0 {
+			end += lineEnd + 1
+			fmt.Fprintf(&buf, "//line %s:%d:1\n", base, self.tf.Line(stmt.End())+1)
+		} else {
+			directive(stmt.Pos())
+		}
+		self.edits = append(self.edits, &edit{start: start, end: end, text: buf.String()})
+		return
+	}
+	directive(stmt.Pos())
+	buf.WriteString(self.render(stmt.Pos(), stmt.End()))
+	self.edits = append(self.edits, &edit{start: start, end: end, text: buf.String()})
+}
+
+// Returns the results returned by the failed ?
+func (self *expansion) failure(t *tryCall) (string, bool) {
+	results := t.sig.Results()
+	if results.Len() == 0 {
+		self.errorf(t.call.Pos(), "? in a function without results")
+		return "", false
+	}
+	qualifier := self.qualifier()
+	last := results.At(results.Len() - 1).Type()
+	switch {
+	case isError(last):
+		if t.kind == option {
+			self.errorf(t.call.Pos(), "? on Option in a function returning error")
+			return "", false
+		}
+		var values []string
+		for i := 0; i < results.Len()-1; i++ {
+			values = append(values, zeroValue(results.At(i).Type(), qualifier))
+		}
+		return strings.Join(append(values, t.status), ", "), true
+	case results.Len() > 1:
+	case kindOf(last) == result || kindOf(last) == resultVoid:
+		if t.kind == option {
+			self.errorf(t.call.Pos(), "? on Option in a function returning Result")
+			return "", false
+		}
+		pkg, ok := self.importName(t, resultPath)
+		if !ok {
+			return "", false
+		}
+		if kindOf(last) == resultVoid {
+			return fmt.Sprintf("%s.Void(%s)", pkg, t.status), true
+		}
+		value := types.TypeString(last.(*types.Named).TypeArgs().At(0), qualifier)
+		return fmt.Sprintf("%s.Err[%s](%s)", pkg, value, t.status), true
+	case kindOf(last) == option:
+		if t.kind != option {
+			self.errorf(t.call.Pos(), "? on an error in a function returning Option")
+			return "", false
+		}
+		pkg, ok := self.importName(t, optionPath)
+		if !ok {
+			return "", false
+		}
+		value := types.TypeString(last.(*types.Named).TypeArgs().At(0), qualifier)
+		return fmt.Sprintf("%s.None[%s]()", pkg, value), true
+	}
+	self.errorf(t.call.Pos(), "? in a function returning %s", results)
+	return "", false
+}
+
+// Returns the name the file imports the package with
+func (self *expansion) importName(t *tryCall, path string) (string, bool) {
+	for _, spec := range self.syntax.Imports {
+		if value, _ := strconv.Unquote(spec.Path.Value); value == path {
+			if spec.Name != nil {
+				return spec.Name.Name, true
+			}
+			return filepath.Base(path), true
+		}
+	}
+	self.errorf(t.call.Pos(), "the file must import %s", path)
+	return "", false
+}
+
+// Qualifies the types by the names the file imports the packages with
+func (self *expansion) qualifier() types.Qualifier {
+	return func(pkg *types.Package) string {
+		if pkg == self.checked {
+			return ""
+		}
+		for _, spec := range self.syntax.Imports {
+			if value, _ := strconv.Unquote(spec.Path.Value); value == pkg.Path() && spec.Name != nil {
+				return spec.Name.Name
+			}
+		}
+		return pkg.Name()
+	}
+}
+
+// Replaces the text between the positions
+func (self *expansion) replace(start, end token.Pos, text string) {
+	self.edits = append(self.edits, &edit{start: self.tf.Offset(start), end: self.tf.Offset(end), text: text})
+}
+
+// Returns the text between the positions with the edits inside applied
+func (self *expansion) render(start, end token.Pos) string {
+	return self.renderOffsets(self.tf.Offset(start), self.tf.Offset(end))
+}
+
+func (self *expansion) renderOffsets(start, end int) string {
+	var inner []*edit
+	for _, e := range self.edits {
+		if !e.consumed && e.start >= start && e.end <= end {
+			inner = append(inner, e)
+		}
+	}
+	sort.SliceStable(inner, func(i, j int) bool { return inner[i].start < inner[j].start })
+	var buf strings.Builder
+	pos := start
+	for _, e := range inner {
+		if e.start < pos {
+			continue
+		}
+		buf.Write(self.text[pos:e.start])
+		buf.WriteString(e.text)
+		pos = e.end
+		e.consumed = true
+	}
+	buf.Write(self.text[pos:end])
+	return buf.String()
+}
+
+// Returns the zero value of the type
+func zeroValue(t types.Type, qualifier types.Qualifier) string {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsBoolean != 0:
+			return "false"
+		case u.Info()&types.IsString != 0:
+			return `""`
+		case u.Info()&types.IsNumeric != 0:
+			return "0"
+		}
+		return "nil"
+	case *types.Pointer, *types.Slice, *types.Map, *types.Chan, *types.Signature:
+		return "nil"
+	case *types.Interface:
+		if _, isParam := t.(*types.TypeParam); !isParam {
+			return "nil"
+		}
+	case *types.Struct, *types.Array:
+		return types.TypeString(t, qualifier) + "{}"
+	}
+	return "*new(" + types.TypeString(t, qualifier) + ")"
+}
+
+// Type-checks the generated files together with the .go files of the package
+func (self *group) verify(generated map[string][]byte) error {
+	files := append([]*ast.File{}, self.files...)
+	for name, text := range generated {
+		syntax, err := parser.ParseFile(self.fset, name, text, parser.ParseComments)
+		if err != nil {
+			return err
+		}
+		files = append(files, syntax)
+	}
+	var errs []error
+	conf := types.Config{
+		Importer: importerFunc(self.importPackage),
+		Error: func(err error) {
+			if len(errs) < 10 {
+				errs = append(errs, fmt.Errorf("%w: %v", ErrType, err))
+			}
+		},
+	}
+	self.checked, _ = conf.Check(self.path, self.fset, files, nil)
+	return errors.Join(errs...)
+}
@@ -0,0 +1,64 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+// Command rustyc translates Go files with the ? operator into Go.
+//
+// The files have the extension .rgo. The suffix ? applies to expressions
+// of type (T, error), error, result.Result[T] and option.Option[T]:
+//
+//	data := os.ReadFile(name)?
+//	port := ParsePort(string(data))?
+//
+// Each ? becomes a check with an early return before the statement,
+// the result depends on the type of the enclosing function:
+// (..., error), Result[T] or Option[T]. The operands of ? are evaluated
+// before the rest of the statement, the inner ones first.
+//
+// The file x.rgo becomes x_rgo.go. The //line directives map
+// the generated code back to the .rgo file for the compiler,
+// stack traces and debuggers.
+//
+// Usage:
+//
+//	rustyc [file.rgo ...]
+//
+// Without arguments all .rgo files in the current directory are translated,
+// which suits go generate:
+//
+//	//go:generate rustyc
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+)
+
+func main() {
+	var cfg Config
+	flag.StringVar(&cfg.Dir, "C", "", "directory of the package (default the current one)")
+	flag.Parse()
+	cfg.Files = flag.Args()
+
+	out, err := Translate(cfg)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	names := make([]string, 0, len(out))
+	for name := range out {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := os.WriteFile(name, out[name], 0o644); err != nil {
+			fatalf("%v", err)
+		}
+	}
+}
+
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "rustyc: "+format+"\n", args...)
+	os.Exit(1)
+}
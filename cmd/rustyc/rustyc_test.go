@@ -0,0 +1,127 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	generated "github.com/pakuula/go-rusty/cmd/rustyc/testdata/try"
+)
+
+var update = flag.Bool("update", false, "update the generated files in testdata")
+
+func TestOutputName(t *testing.T) {
+	assert.Equal(t, "x_rgo.go", outputName("x.rgo"))
+	assert.Equal(t, "dir/x_rgo_test.go", outputName("dir/x_test.rgo"))
+}
+
+// The generated file is up to date
+func TestTranslate(t *testing.T) {
+	out, err := Translate(Config{Dir: "testdata/try"})
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	name, err := filepath.Abs("testdata/try/try_rgo.go")
+	require.NoError(t, err)
+	src, ok := out[name]
+	require.True(t, ok)
+	if *update {
+		require.NoError(t, os.WriteFile(name, src, 0o644))
+	}
+	expected, err := os.ReadFile(name)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(src))
+}
+
+func TestGenerated(t *testing.T) {
+	port, err := generated.ParsePort(" 8080 ")
+	require.NoError(t, err)
+	assert.Equal(t, 8080, port)
+
+	_, err = generated.ParsePort("x")
+	assert.ErrorContains(t, err, "invalid syntax")
+	_, err = generated.ParsePort("0")
+	assert.ErrorIs(t, err, generated.ErrRange)
+
+	sum, err := generated.Sum("1", "2")
+	require.NoError(t, err)
+	assert.Equal(t, 3, sum)
+	_, err = generated.Sum("1", "x")
+	assert.Error(t, err)
+
+	ports, err := generated.Ports([]string{"1", "2"})
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, ports)
+	_, err = generated.Ports([]string{"1", "70000"})
+	assert.ErrorIs(t, err, generated.ErrRange)
+
+	assert.True(t, generated.LoadConfig("testdata/missing").IsError())
+	_, err = generated.Name("testdata/missing")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	assert.True(t, generated.Save(generated.Config{}).IsError())
+
+	assert.Equal(t, 12, generated.Width(map[string]string{"COLUMNS": "eighty-eight"}).Unwrap())
+	assert.True(t, generated.Width(nil).IsNone())
+}
+
+// The line directives point to the .rgo file
+func TestLines(t *testing.T) {
+	src, err := os.ReadFile("testdata/try/try.rgo")
+	require.NoError(t, err)
+	line := 1 + strings.Index(string(src), "runtime.Caller(0)")
+	line = 1 + strings.Count(string(src[:line]), "\n")
+
+	here, err := generated.Here()
+	require.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("try.rgo:%d:80", line), here)
+}
+
+func TestErrors(t *testing.T) {
+	_, err := Translate(Config{Dir: "testdata/bad", Files: []string{"bad.rgo"}})
+	require.ErrorIs(t, err, ErrTry)
+	messages := strings.Split(err.Error(), "\n")
+	for i, message := range messages {
+		messages[i] = message[strings.LastIndex(message, "bad.rgo:"):]
+	}
+	assert.Equal(t, []string{
+		"bad.rgo:14: unsupported ?: ? in a function without results",
+		"bad.rgo:18: unsupported ?: ? on Option in a function returning error",
+		"bad.rgo:23: unsupported ?: ? on an error in a function returning Option",
+		"bad.rgo:27: unsupported ?: ? in the right operand of &&",
+		"bad.rgo:31: unsupported ?: ? in the condition or the post statement of for",
+		"bad.rgo:39: unsupported ?: ? in the condition of else if",
+		"bad.rgo:46: unsupported ?: ? on error or ResultVoid must be a statement",
+	}, messages)
+
+	_, err = Translate(Config{Dir: "testdata/bad", Files: []string{"kind.rgo"}})
+	assert.ErrorIs(t, err, ErrType)
+	assert.ErrorContains(t, err, "kind.rgo:4:9: type error: ? applied to int")
+
+	_, err = Translate(Config{Dir: "testdata/bad", Files: []string{"syntax.rgo"}})
+	assert.ErrorIs(t, err, ErrSyntax)
+	assert.ErrorContains(t, err, "syntax.rgo:4:9: syntax error: expected operand, found '?'")
+	assert.False(t, errors.Is(err, ErrTry))
+}
+
+func BenchmarkTry(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		generated.ParseResult("x") //rusty:ignore the benchmark measures the call
+	}
+}
+
+func BenchmarkCatch(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		generated.ParseCatch("x") //rusty:ignore the benchmark measures the call
+	}
+}
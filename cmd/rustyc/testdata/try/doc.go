@@ -0,0 +1,4 @@
+// Package try is translated by rustyc from try.rgo.
+package try
+
+//go:generate go run -C ../../.. ./rustyc -C rustyc/testdata/try
@@ -0,0 +1,577 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+const (
+	resultPath = "github.com/pakuula/go-rusty/result"
+	optionPath = "github.com/pakuula/go-rusty/option"
+	// The ? operator is parsed as the call of the method rustycTry<N>_
+	marker = "rustycTry"
+)
+
+var repeatedDirective = regexp.MustCompile(`(?m)^//line [^\n]*\n(//line )`)
+
+var (
+	ErrSyntax = errors.New("syntax error")
+	ErrType   = errors.New("type error")
+	ErrTry    = errors.New("unsupported ?")
+)
+
+// Settings of the translator
+type Config struct {
+	// Directory of the package, the current one if empty
+	Dir string
+	// The .rgo files to translate, all in Dir if empty
+	Files []string
+}
+
+// The kind of the operand of ?
+type kind int
+
+const (
+	unknown    kind = iota
+	withError       // (T, error)
+	errorOnly       // error
+	result          // Result[T]
+	resultVoid      // ResultVoid
+	option          // Option[T]
+)
+
+// An occurrence of the ? operator
+type try struct {
+	id   int
+	kind kind
+	// The operand and the marker in the marked source
+	x, end int
+}
+
+// A .rgo file
+type source struct {
+	name    string // path of the .rgo file
+	pkgName string
+	marked  []byte // the source with ? replaced by the marker calls
+	variant []byte // the marked source with the known ? replaced by the helper calls
+	tries   []*try
+	// Offsets of the ? in the original source and the marked one
+	qmarks, markers []int
+}
+
+// Returns the name of the generated file: x.rgo becomes x_rgo.go, x_test.rgo becomes x_rgo_test.go
+func outputName(name string) string {
+	base := strings.TrimSuffix(name, ".rgo")
+	if strings.HasSuffix(base, "_test") {
+		return strings.TrimSuffix(base, "_test") + "_rgo_test.go"
+	}
+	return base + "_rgo.go"
+}
+
+// Translates the .rgo files and returns the generated files by name
+func Translate(cfg Config) (map[string][]byte, error) {
+	dir := cfg.Dir
+	if dir == "" {
+		dir = "."
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	names := cfg.Files
+	if len(names) == 0 {
+		if names, err = filepath.Glob(filepath.Join(dir, "*.rgo")); err != nil {
+			return nil, err
+		}
+	}
+	fset := token.NewFileSet()
+	var sources []*source
+	imports := map[string]bool{resultPath: true, optionPath: true}
+	for _, name := range names {
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(dir, name)
+		}
+		src, err := readSource(fset, name)
+		if err != nil {
+			return nil, err
+		}
+		for path := range src.imports(fset) {
+			imports[path] = true
+		}
+		sources = append(sources, src)
+	}
+	if len(sources) == 0 {
+		return map[string][]byte{}, nil
+	}
+
+	patterns := []string{"."}
+	for path := range imports {
+		patterns = append(patterns, path)
+	}
+	sort.Strings(patterns[1:])
+	mode := packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedTypes
+	loaded, err := packages.Load(&packages.Config{Mode: mode, Dir: dir, Tests: true}, patterns...)
+	if err != nil {
+		return nil, err
+	}
+	deps := map[string]*types.Package{}
+	packages.Visit(loaded, nil, func(pkg *packages.Package) {
+		if _, ok := deps[pkg.PkgPath]; !ok && pkg.Types != nil && pkg.Types.Complete() {
+			deps[pkg.PkgPath] = pkg.Types
+		}
+	})
+
+	// The package is translated before its external tests
+	sort.SliceStable(sources, func(i, j int) bool {
+		return !strings.HasSuffix(sources[i].pkgName, "_test") && strings.HasSuffix(sources[j].pkgName, "_test")
+	})
+	generated := map[string]bool{}
+	for _, src := range sources {
+		generated[outputName(src.name)] = true
+	}
+	out := map[string][]byte{}
+	var pkgNames []string
+	groups := map[string][]*source{}
+	for _, src := range sources {
+		if groups[src.pkgName] == nil {
+			pkgNames = append(pkgNames, src.pkgName)
+		}
+		groups[src.pkgName] = append(groups[src.pkgName], src)
+	}
+	for _, name := range pkgNames {
+		g := &group{fset: fset, name: name, sources: groups[name], deps: deps}
+		if err := g.findFiles(loaded, dir, generated); err != nil {
+			return nil, err
+		}
+		if err := g.translate(out); err != nil {
+			return nil, err
+		}
+		if !strings.HasSuffix(name, "_test") && g.checked != nil {
+			// The external tests import the translated package
+			deps[g.path] = g.checked
+		}
+	}
+	return out, nil
+}
+
+// Reads the .rgo file and replaces ? by the marker calls
+func readSource(fset *token.FileSet, name string) (*source, error) {
+	text, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	src := &source{name: name}
+
+	var s scanner.Scanner
+	var errs scanner.ErrorList
+	tf := fset.AddFile(name, -1, len(text))
+	s.Init(tf, text, func(pos token.Position, msg string) { errs.Add(pos, msg) }, 0)
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.ILLEGAL && lit == "?" {
+			src.qmarks = append(src.qmarks, tf.Offset(pos))
+		}
+	}
+	// The errors other than ? are reported by the parser
+	var marked []byte
+	prev := 0
+	for i, offset := range src.qmarks {
+		marked = append(marked, text[prev:offset]...)
+		src.markers = append(src.markers, len(marked))
+		marked = append(marked, fmt.Sprintf(".%s%d_()", marker, i+1)...)
+		prev = offset + 1
+	}
+	src.marked = append(marked, text[prev:]...)
+
+	syntax, err := parser.ParseFile(fset, name, src.marked, parser.ParseComments)
+	if err != nil {
+		return nil, src.syntaxError(err)
+	}
+	src.pkgName = syntax.Name.Name
+	ast.Inspect(syntax, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok && len(call.Args) == 0 {
+			if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+				if id := markerID(sel.Sel.Name); id > 0 {
+					tf := fset.File(call.Pos())
+					src.tries = append(src.tries, &try{id: id, x: tf.Offset(sel.X.Pos()), end: tf.Offset(call.End())})
+				}
+			}
+		}
+		return true
+	})
+	if len(src.tries) != len(src.qmarks) {
+		found := map[int]bool{}
+		for _, t := range src.tries {
+			found[t.id] = true
+		}
+		for i := range src.qmarks {
+			if !found[i+1] {
+				return nil, src.errorf(fset, src.markers[i], ErrSyntax, "unexpected ?")
+			}
+		}
+	}
+	sort.Slice(src.tries, func(i, j int) bool { return src.tries[i].id < src.tries[j].id })
+	return src, nil
+}
+
+// Returns N of the marker name rustycTry<N>_, 0 for other names
+func markerID(name string) int {
+	if !strings.HasPrefix(name, marker) || !strings.HasSuffix(name, "_") {
+		return 0
+	}
+	id, err := strconv.Atoi(name[len(marker) : len(name)-1])
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+// Returns the import paths of the file
+func (self *source) imports(fset *token.FileSet) map[string]bool {
+	paths := map[string]bool{}
+	syntax, err := parser.ParseFile(fset, self.name, self.marked, parser.ImportsOnly)
+	if err != nil {
+		return paths
+	}
+	for _, spec := range syntax.Imports {
+		if path, err := strconv.Unquote(spec.Path.Value); err == nil {
+			paths[path] = true
+		}
+	}
+	return paths
+}
+
+// Returns the offset in the original source of the offset in the marked one
+func (self *source) originalOffset(offset int) int {
+	shift := 0
+	for i, m := range self.markers {
+		if m >= offset {
+			break
+		}
+		shift += len(fmt.Sprintf(".%s%d_()", marker, i+1)) - 1
+	}
+	return offset - shift
+}
+
+// Returns the position in the original source of the offset in the marked one
+func (self *source) position(fset *token.FileSet, offset int) string {
+	offset = self.originalOffset(offset)
+	text, _ := os.ReadFile(self.name)
+	line, col := 1, 1
+	for _, c := range text[:min(offset, len(text))] {
+		if c == '\n' {
+			line, col = line+1, 1
+		} else {
+			col++
+		}
+	}
+	return fmt.Sprintf("%s:%d:%d", self.name, line, col)
+}
+
+func (self *source) errorf(fset *token.FileSet, offset int, kind error, format string, args ...any) error {
+	return fmt.Errorf("%s: %w: %s", self.position(fset, offset), kind, fmt.Sprintf(format, args...))
+}
+
+// Maps the positions of the syntax errors to the original source
+func (self *source) syntaxError(err error) error {
+	var list scanner.ErrorList
+	if !errors.As(err, &list) {
+		return err
+	}
+	// The errors after the first one are mostly the consequences
+	e := list[0]
+	msg := e.Msg
+	for _, m := range self.markers {
+		if m == e.Pos.Offset {
+			msg = strings.Replace(msg, "'.'", "'?'", 1)
+		}
+	}
+	return fmt.Errorf("%s: %w: %s", self.positionOf(e.Pos), ErrSyntax, msg)
+}
+
+// Returns the position in the original source of the position in the marked one
+func (self *source) positionOf(pos token.Position) string {
+	// The markers don't span lines, only the column changes
+	lineStart := pos.Offset - (pos.Column - 1)
+	col := self.originalOffset(pos.Offset) - self.originalOffset(lineStart) + 1
+	return fmt.Sprintf("%s:%d:%d", self.name, pos.Line, col)
+}
+
+// The .rgo files of a package together with its .go files
+type group struct {
+	fset    *token.FileSet
+	name    string // package name
+	path    string // import path
+	sources []*source
+	files   []*ast.File // the .go files
+	deps    map[string]*types.Package
+	checked *types.Package
+}
+
+// Parses the .go files of the package, the files generated from the .rgo files are skipped
+func (self *group) findFiles(loaded []*packages.Package, dir string, generated map[string]bool) error {
+	var pkg *packages.Package
+	for _, p := range loaded {
+		if p.Name != self.name || len(p.GoFiles) == 0 || filepath.Dir(p.GoFiles[0]) != dir {
+			continue
+		}
+		// The test variant has more files
+		if pkg == nil || len(p.GoFiles) > len(pkg.GoFiles) {
+			pkg = p
+		}
+	}
+	self.path = self.name
+	if pkg == nil {
+		return nil
+	}
+	self.path = pkg.PkgPath
+	for _, name := range pkg.GoFiles {
+		if generated[name] {
+			continue
+		}
+		syntax, err := parser.ParseFile(self.fset, name, nil, parser.ParseComments)
+		if err != nil {
+			return err
+		}
+		self.files = append(self.files, syntax)
+	}
+	return nil
+}
+
+// Determines the kinds of the operands of ? and translates the files
+func (self *group) translate(out map[string][]byte) error {
+	for {
+		files, info, errs := self.check()
+		progress, done := false, true
+		for _, src := range self.sources {
+			for _, t := range src.tries {
+				if t.kind != unknown {
+					continue
+				}
+				done = false
+				typ := operandType(info, files[src], t.id)
+				if typ == nil || typ == types.Typ[types.Invalid] {
+					continue
+				}
+				if t.kind = kindOf(typ); t.kind == unknown {
+					return src.errorf(self.fset, t.x, ErrType, "? applied to %s", typ)
+				}
+				progress = true
+			}
+		}
+		if done {
+			if len(errs) > 0 {
+				return self.typeError(errs)
+			}
+			return self.expand(files, info, out)
+		}
+		if !progress {
+			if len(errs) > 0 {
+				return self.typeError(errs)
+			}
+			return fmt.Errorf("%s: %w: cannot determine the type of the operand of ?", self.name, ErrType)
+		}
+	}
+}
+
+// Type-checks the package with the known ? replaced by the calls of the helper functions
+func (self *group) check() (map[*source]*ast.File, *types.Info, []types.Error) {
+	files := map[*source]*ast.File{}
+	all := append([]*ast.File{}, self.files...)
+	var helpers strings.Builder
+	fmt.Fprintf(&helpers, "package %s\n\nimport (\n\trustycResult_ %q\n\trustycOption_ %q\n)\n\n", self.name, resultPath, optionPath)
+	helpers.WriteString("var _ rustycResult_.ResultVoid\nvar _ rustycOption_.Option[int]\n")
+	for _, src := range self.sources {
+		var edits []edit
+		for _, t := range src.tries {
+			if t.kind == unknown {
+				continue
+			}
+			name := fmt.Sprintf("%s%d_", marker, t.id)
+			edits = append(edits, edit{start: t.x, end: t.x, text: name + "("})
+			edits = append(edits, edit{start: t.end - len(name) - 3, end: t.end, text: ")"})
+			switch t.kind {
+			case withError:
+				fmt.Fprintf(&helpers, "func %s[T any](v T, err error) T { panic(nil) }\n", name)
+			case errorOnly:
+				fmt.Fprintf(&helpers, "func %s(err error) struct{} { panic(nil) }\n", name)
+			case result, resultVoid:
+				fmt.Fprintf(&helpers, "func %s[T any](r rustycResult_.Result[T]) T { panic(nil) }\n", name)
+			case option:
+				fmt.Fprintf(&helpers, "func %s[T any](o rustycOption_.Option[T]) T { panic(nil) }\n", name)
+			}
+		}
+		src.variant = applyEdits(src.marked, edits)
+		syntax, err := parser.ParseFile(self.fset, src.name, src.variant, parser.ParseComments)
+		if err != nil {
+			// The markers are replaced by valid expressions
+			panic(err)
+		}
+		files[src] = syntax
+		all = append(all, syntax)
+	}
+	helperFile, err := parser.ParseFile(self.fset, "rustyc_helpers.go", helpers.String(), 0)
+	if err != nil {
+		panic(err)
+	}
+	all = append(all, helperFile)
+
+	info := &types.Info{
+		Types: map[ast.Expr]types.TypeAndValue{},
+		Defs:  map[*ast.Ident]types.Object{},
+		Uses:  map[*ast.Ident]types.Object{},
+	}
+	var errs []types.Error
+	conf := types.Config{
+		Importer: importerFunc(self.importPackage),
+		Error: func(err error) {
+			if e, ok := err.(types.Error); ok && !strings.Contains(e.Msg, marker) {
+				errs = append(errs, e)
+			}
+		},
+	}
+	self.checked, _ = conf.Check(self.path, self.fset, all, info)
+	return files, info, errs
+}
+
+func (self *group) importPackage(path string) (*types.Package, error) {
+	if path == "unsafe" {
+		return types.Unsafe, nil
+	}
+	if pkg, ok := self.deps[path]; ok {
+		return pkg, nil
+	}
+	return nil, fmt.Errorf("package %s is not loaded", path)
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (self importerFunc) Import(path string) (*types.Package, error) { return self(path) }
+
+// Returns the type of the operand of the ? with the id
+func operandType(info *types.Info, syntax *ast.File, id int) types.Type {
+	var typ types.Type
+	name := fmt.Sprintf("%s%d_", marker, id)
+	ast.Inspect(syntax, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || typ != nil {
+			return typ == nil
+		}
+		if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == name {
+			typ = info.TypeOf(sel.X)
+		}
+		return true
+	})
+	return typ
+}
+
+// Returns the kind of the operand of ?
+func kindOf(t types.Type) kind {
+	if tuple, ok := t.(*types.Tuple); ok {
+		if tuple.Len() == 2 && isError(tuple.At(1).Type()) {
+			return withError
+		}
+		return unknown
+	}
+	if isError(t) {
+		return errorOnly
+	}
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return unknown
+	}
+	switch named.Obj().Pkg().Path() + "." + named.Obj().Name() {
+	case resultPath + ".Result":
+		if isVoid(named.TypeArgs().At(0)) {
+			return resultVoid
+		}
+		return result
+	case optionPath + ".Option":
+		return option
+	}
+	return unknown
+}
+
+func isError(t types.Type) bool {
+	return types.Identical(t, types.Universe.Lookup("error").Type())
+}
+
+// Reports if the type is the value type of ResultVoid
+func isVoid(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == resultPath && named.Obj().Name() == "_Void"
+}
+
+// Reports the type errors at the positions of the original source
+func (self *group) typeError(errs []types.Error) error {
+	var joined []error
+	for _, e := range errs {
+		pos := self.fset.Position(e.Pos)
+		name := pos.String()
+		for _, src := range self.sources {
+			if src.name == pos.Filename {
+				name = fmt.Sprintf("%s:%d", pos.Filename, pos.Line)
+			}
+		}
+		joined = append(joined, fmt.Errorf("%s: %w: %s", name, ErrType, e.Msg))
+		if len(joined) == 10 {
+			break
+		}
+	}
+	return errors.Join(joined...)
+}
+
+// Replaces the text between the offsets
+type edit struct {
+	start, end int
+	text       string
+	consumed   bool // applied as a part of an enclosing edit
+}
+
+// Applies non-overlapping edits
+func applyEdits(src []byte, edits []edit) []byte {
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+	var out []byte
+	pos := 0
+	for _, e := range edits {
+		out = append(out, src[pos:e.start]...)
+		out = append(out, e.text...)
+		pos = e.end
+	}
+	return append(out, src[pos:]...)
+}
+
+// Formats the generated file and checks it together with the package
+func (self *group) finish(src *source, text []byte) ([]byte, error) {
+	header := fmt.Sprintf("// Code generated by rustyc from %s; DO NOT EDIT.\n\n//line %s:1:1\n",
+		filepath.Base(src.name), filepath.Base(src.name))
+	text = append([]byte(header), text...)
+	// A directive followed by another one has no effect
+	text = repeatedDirective.ReplaceAll(text, []byte("$1"))
+	formatted, err := format.Source(text)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", src.name, err)
+	}
+	return formatted, nil
+}
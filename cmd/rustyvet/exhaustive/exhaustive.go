@@ -0,0 +1,198 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+// Package exhaustive reports type switches over enums that miss a variant.
+//
+// An enum is an interface whose declaration has the comment //rusty:enum,
+// such as the interfaces generated by rusty-enum. Its variants are the types
+// of its package implementing it. A type switch over an enum needs a case for
+// each variant or a default clause.
+//
+// A pointer to a variant implements the enum too. A switch with a case for
+// a pointer to a variant needs a case for the pointers to the other variants.
+package exhaustive
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const directive = "//rusty:enum"
+
+var Analyzer = &analysis.Analyzer{
+	Name:      "exhaustive",
+	Doc:       "report type switches over enums that miss a variant",
+	Requires:  []*analysis.Analyzer{inspect.Analyzer},
+	Run:       run,
+	FactTypes: []analysis.Fact{new(enumFact)},
+}
+
+// Marks the enums and lists their variants
+type enumFact struct {
+	Variants []string
+}
+
+func (*enumFact) AFact() {}
+func (self *enumFact) String() string {
+	return "enum(" + strings.Join(self.Variants, ", ") + ")"
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				spec := spec.(*ast.TypeSpec)
+				doc := spec.Doc
+				if doc == nil && len(gen.Specs) == 1 {
+					doc = gen.Doc
+				}
+				if hasDirective(doc) {
+					exportEnum(pass, spec)
+				}
+			}
+		}
+	}
+
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	inspect.Preorder([]ast.Node{(*ast.TypeSwitchStmt)(nil)}, func(n ast.Node) {
+		check(pass, n.(*ast.TypeSwitchStmt))
+	})
+	return nil, nil
+}
+
+func hasDirective(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+	for _, c := range doc.List {
+		if rest, ok := strings.CutPrefix(c.Text, directive); ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+			return true
+		}
+	}
+	return false
+}
+
+// Records the variants of the enum declared by the spec
+func exportEnum(pass *analysis.Pass, spec *ast.TypeSpec) {
+	obj, ok := pass.TypesInfo.Defs[spec.Name].(*types.TypeName)
+	if !ok || spec.TypeParams != nil {
+		return
+	}
+	iface, ok := obj.Type().Underlying().(*types.Interface)
+	if !ok {
+		return
+	}
+	var variants []*types.TypeName
+	scope := pass.Pkg.Scope()
+	for _, name := range scope.Names() {
+		v, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || v.IsAlias() || types.IsInterface(v.Type()) {
+			continue
+		}
+		if named, ok := v.Type().(*types.Named); ok && named.TypeParams().Len() == 0 && types.Implements(named, iface) {
+			variants = append(variants, v)
+		}
+	}
+	if len(variants) == 0 {
+		return
+	}
+	sort.Slice(variants, func(i, j int) bool { return variants[i].Pos() < variants[j].Pos() })
+	fact := &enumFact{}
+	for _, v := range variants {
+		fact.Variants = append(fact.Variants, v.Name())
+	}
+	pass.ExportObjectFact(obj, fact)
+}
+
+func check(pass *analysis.Pass, stmt *ast.TypeSwitchStmt) {
+	var x ast.Expr
+	switch assign := stmt.Assign.(type) {
+	case *ast.ExprStmt:
+		x = assign.X
+	case *ast.AssignStmt:
+		x = assign.Rhs[0]
+	}
+	assert, ok := x.(*ast.TypeAssertExpr)
+	if !ok {
+		return
+	}
+	named, ok := types.Unalias(pass.TypesInfo.TypeOf(assert.X)).(*types.Named)
+	if !ok {
+		return
+	}
+	var fact enumFact
+	if !pass.ImportObjectFact(named.Obj(), &fact) {
+		return
+	}
+
+	// The variants followed by the pointers to them
+	var names []string
+	variants := map[string]types.Type{}
+	for _, name := range fact.Variants {
+		if v, ok := named.Obj().Pkg().Scope().Lookup(name).(*types.TypeName); ok {
+			names = append(names, name)
+			variants[name] = v.Type()
+		}
+	}
+	for _, name := range names {
+		variants["*"+name] = types.NewPointer(variants[name])
+	}
+	for _, name := range fact.Variants {
+		if _, ok := variants[name]; ok {
+			names = append(names, "*"+name)
+		}
+	}
+
+	covered := map[string]bool{}
+	pointers := false
+	for _, clause := range stmt.Body.List {
+		clause := clause.(*ast.CaseClause)
+		if clause.List == nil {
+			// default
+			return
+		}
+		for _, expr := range clause.List {
+			tv, ok := pass.TypesInfo.Types[expr]
+			if !ok || tv.IsNil() {
+				continue
+			}
+			for name, t := range variants {
+				iface, isIface := tv.Type.Underlying().(*types.Interface)
+				switch {
+				case isIface && types.Implements(t, iface):
+					covered[name] = true
+				case types.Identical(t, tv.Type):
+					covered[name] = true
+					pointers = pointers || strings.HasPrefix(name, "*")
+				}
+			}
+		}
+	}
+	var missing []string
+	for _, name := range names {
+		if !covered[name] && (pointers || !strings.HasPrefix(name, "*")) {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		qualifier := func(pkg *types.Package) string {
+			if pkg == pass.Pkg {
+				return ""
+			}
+			return pkg.Name()
+		}
+		pass.Reportf(stmt.Pos(), "type switch over %s misses %s", types.TypeString(named, qualifier), strings.Join(missing, ", "))
+	}
+}
@@ -0,0 +1,16 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package exhaustive_test
+
+import (
+	"testing"
+
+	"github.com/pakuula/go-rusty/cmd/rustyvet/exhaustive"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), exhaustive.Analyzer, "./a", "./b")
+}
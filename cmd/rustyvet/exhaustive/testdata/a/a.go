@@ -0,0 +1,73 @@
+package a
+
+import "fmt"
+
+// The enum declared by hand
+//
+//rusty:enum
+type Shape interface { // want Shape:`enum\(Circle, Rect, Dot\)`
+	isShape()
+}
+
+type Circle struct{ Radius float64 }
+type Rect struct{ Width, Height float64 }
+type Dot struct{}
+
+func (Circle) isShape() {}
+func (Rect) isShape()   {}
+func (Dot) isShape()    {}
+
+// Not a variant, the method has a pointer receiver
+type Pointer struct{}
+
+func (*Pointer) isShape() {}
+
+// Not an enum
+type Stringer interface {
+	String() string
+}
+
+func Area(s Shape) float64 {
+	switch s := s.(type) { // want `type switch over Shape misses Dot`
+	case Circle:
+		return 3 * s.Radius * s.Radius
+	case Rect:
+		return s.Width * s.Height
+	}
+	return 0
+}
+
+func Name(s Shape) string {
+	switch s.(type) {
+	case Circle, Rect, Dot:
+		return "known"
+	case nil:
+		return "nil"
+	}
+	return ""
+}
+
+func Default(s Shape) string {
+	switch s.(type) {
+	case Circle:
+		return "circle"
+	default:
+		return "other"
+	}
+}
+
+func Any(s any) string {
+	switch s.(type) {
+	case Circle:
+		return "circle"
+	}
+	return ""
+}
+
+func Other(s fmt.Stringer) string {
+	switch s.(type) {
+	case Stringer:
+		return "stringer"
+	}
+	return ""
+}
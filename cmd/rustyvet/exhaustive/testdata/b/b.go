@@ -0,0 +1,56 @@
+package b
+
+import "example.com/exhaustive/a"
+
+func Kind(s a.Shape) string {
+	switch s.(type) { // want `type switch over a.Shape misses Rect, Dot`
+	case a.Circle, nil:
+		return "circle"
+	}
+	return ""
+}
+
+// The interface case covers the variants implementing it
+func Covered(s a.Shape) string {
+	switch s.(type) {
+	case a.Shape:
+		return "shape"
+	}
+	return ""
+}
+
+func Nested(s a.Shape) {
+	_ = func() {
+		switch v := s.(type) { // want `type switch over a.Shape misses Circle`
+		case a.Rect, a.Dot:
+			_ = v
+		}
+	}
+}
+
+// A pointer case needs the pointers to the other variants
+func Pointers(s a.Shape) string {
+	switch s.(type) { // want `type switch over a.Shape misses \*Rect, \*Dot`
+	case a.Circle, a.Rect, a.Dot:
+		return "value"
+	case *a.Circle:
+		return "pointer"
+	}
+	return ""
+}
+
+func PointerOnly(s a.Shape) string {
+	switch s.(type) { // want `type switch over a.Shape misses Circle, \*Rect, \*Dot`
+	case *a.Circle, a.Rect, a.Dot:
+		return "shape"
+	}
+	return ""
+}
+
+func AllPointers(s a.Shape) string {
+	switch s.(type) {
+	case a.Circle, a.Rect, a.Dot, *a.Circle, *a.Rect, *a.Dot:
+		return "shape"
+	}
+	return ""
+}
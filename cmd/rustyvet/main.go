@@ -0,0 +1,26 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+// Command rustyvet checks the use of Result and Option values:
+// the panics of Must are caught and the values are not discarded.
+// It also reports the type switches over enums that miss a variant.
+//
+// Usage:
+//
+//	rustyvet ./...
+//	go vet -vettool=$(which rustyvet) ./...
+//
+// Pass -fix to apply the suggested fixes.
+package main
+
+import (
+	"github.com/pakuula/go-rusty/cmd/rustyvet/exhaustive"
+	"github.com/pakuula/go-rusty/cmd/rustyvet/mustcatch"
+	"github.com/pakuula/go-rusty/cmd/rustyvet/mustuse"
+	"golang.org/x/tools/go/analysis/multichecker"
+)
+
+func main() {
+	multichecker.Main(exhaustive.Analyzer, mustcatch.Analyzer, mustuse.Analyzer)
+}
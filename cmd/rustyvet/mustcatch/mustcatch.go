@@ -0,0 +1,436 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+// Package mustcatch checks that the panics of Must are caught by a deferred Catch.
+//
+// It reports
+//   - Must, Mustf, NoError, MustOk and Option.Must in functions without a deferred Catch,
+//     only Catch deferred by a statement of the function body before the call counts,
+//   - Must in goroutines whose function doesn't defer Catch,
+//     tests are not checked for these two, a panic just fails the test,
+//   - Catch and CatchError that are not called by a defer statement,
+//   - Catch pointers that don't point at a named result of the function.
+package mustcatch
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const (
+	resultPath = "github.com/pakuula/go-rusty/result"
+	optionPath = "github.com/pakuula/go-rusty/option"
+)
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "mustcatch",
+	Doc:      "check that the panics of Must are caught by a deferred Catch",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// The kind of the panic: Must of results is caught by result.Catch and
+// result.CatchError, Must of options is caught by option.Catch.
+type kind int
+
+const (
+	none kind = iota
+	resultKind
+	optionKind
+)
+
+func (self kind) catcher() string {
+	if self == optionKind {
+		return "option.Catch"
+	}
+	return "result.Catch"
+}
+
+// Returns the name and the kind of a call to Must
+func mustCall(info *types.Info, call *ast.CallExpr) (string, kind) {
+	fn, ok := typeutil.Callee(info, call).(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return "", none
+	}
+	fn = fn.Origin()
+	recv := ""
+	if sig := fn.Type().(*types.Signature); sig.Recv() != nil {
+		if named, ok := types.Unalias(sig.Recv().Type()).(*types.Named); ok {
+			recv = named.Obj().Name()
+		}
+	}
+	switch fn.Pkg().Path() + "." + recv + "." + fn.Name() {
+	case resultPath + "..Must", resultPath + "..NoError", resultPath + ".Result.Must", resultPath + ".Result.Mustf":
+		return fn.Name(), resultKind
+	case optionPath + "..MustOk", optionPath + ".Option.Must":
+		return fn.Name(), optionKind
+	}
+	return "", none
+}
+
+// Returns the name and the kind of a call to Catch
+func catchCall(info *types.Info, call *ast.CallExpr) (string, kind) {
+	fn, ok := typeutil.Callee(info, call).(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return "", none
+	}
+	switch fn.Pkg().Path() + "." + fn.Name() {
+	case resultPath + ".Catch":
+		return "result.Catch", resultKind
+	case resultPath + ".CatchError":
+		return "result.CatchError", resultKind
+	case optionPath + ".Catch":
+		return "option.Catch", optionKind
+	}
+	return "", none
+}
+
+// A function declaration or literal
+type function struct {
+	typ       *ast.FuncType
+	body      *ast.BlockStmt
+	parent    *function
+	goroutine bool               // the function literal is started by a go statement
+	deferStmt *ast.DeferStmt     // the defer statement calling the function literal
+	catches   map[kind]token.Pos // kinds of the Catch calls deferred by the statements of the body
+	late      map[kind]bool      // kinds of the Catch calls deferred conditionally or in nested blocks
+	fixed     bool               // a fix adding Catch is already suggested
+	inTest    bool               // a panic only fails the test
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	inspect.Preorder([]ast.Node{(*ast.FuncDecl)(nil)}, func(n ast.Node) {
+		if decl := n.(*ast.FuncDecl); decl.Body != nil {
+			inTest := strings.HasSuffix(pass.Fset.File(decl.Pos()).Name(), "_test.go")
+			check(pass, &function{typ: decl.Type, body: decl.Body, inTest: inTest})
+		}
+	})
+	return nil, nil
+}
+
+func check(pass *analysis.Pass, fn *function) {
+	fn.catches = map[kind]token.Pos{}
+	fn.late = map[kind]bool{}
+	deferred := map[*ast.CallExpr]bool{}
+	inspectBody(fn.body, func(n ast.Node) {
+		if stmt, ok := n.(*ast.DeferStmt); ok {
+			if _, k := catchCall(pass.TypesInfo, stmt.Call); k != none {
+				fn.late[k] = true
+				deferred[stmt.Call] = true
+			}
+		}
+	})
+	// A panic before the defer statement or with the defer skipped escapes
+	for _, stmt := range fn.body.List {
+		if stmt, ok := stmt.(*ast.DeferStmt); ok {
+			if _, k := catchCall(pass.TypesInfo, stmt.Call); k != none {
+				if _, ok := fn.catches[k]; !ok {
+					fn.catches[k] = stmt.Pos()
+				}
+			}
+		}
+	}
+
+	goroutines := map[*ast.FuncLit]bool{}
+	defers := map[*ast.FuncLit]*ast.DeferStmt{}
+	inspectBody(fn.body, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.GoStmt:
+			if lit, ok := n.Call.Fun.(*ast.FuncLit); ok {
+				goroutines[lit] = true
+			}
+		case *ast.DeferStmt:
+			if lit, ok := n.Call.Fun.(*ast.FuncLit); ok {
+				defers[lit] = n
+			}
+		case *ast.FuncLit:
+			check(pass, &function{typ: n.Type, body: n.Body, parent: fn, goroutine: goroutines[n], deferStmt: defers[n], inTest: fn.inTest})
+		case *ast.CallExpr:
+			if name, k := catchCall(pass.TypesInfo, n); k != none {
+				if deferred[n] {
+					checkPointer(pass, fn, n, name)
+				} else {
+					reportNotDeferred(pass, fn, n, name)
+				}
+			} else if name, k := mustCall(pass.TypesInfo, n); k != none && !fn.inTest {
+				checkMust(pass, fn, n, name, k)
+			}
+		}
+	})
+}
+
+// Inspects the body of the function, function literals are visited but not entered
+func inspectBody(body *ast.BlockStmt, f func(ast.Node)) {
+	ast.Inspect(body, func(n ast.Node) bool {
+		if n == nil {
+			return false
+		}
+		f(n)
+		_, isLit := n.(*ast.FuncLit)
+		return !isLit
+	})
+}
+
+func checkMust(pass *analysis.Pass, fn *function, call *ast.CallExpr, name string, k kind) {
+	for f := fn; f != nil; f = f.parent {
+		// A function literal may be called after the defer of its parent
+		if pos, ok := f.catches[k]; ok && (f != fn || pos < call.Pos()) {
+			return
+		}
+		if f.goroutine {
+			pass.Report(analysis.Diagnostic{
+				Pos:            call.Pos(),
+				End:            call.End(),
+				Message:        fmt.Sprintf("%s in a goroutine is not caught by the enclosing function; defer %s in the goroutine", name, k.catcher()),
+				SuggestedFixes: catchFixes(pass, f, k),
+			})
+			return
+		}
+	}
+	if fn.late[k] {
+		pass.Report(analysis.Diagnostic{
+			Pos:     call.Pos(),
+			End:     call.End(),
+			Message: fmt.Sprintf("%s is not covered by the deferred %s; defer it unconditionally at the start of the function", name, k.catcher()),
+		})
+		return
+	}
+	pass.Report(analysis.Diagnostic{
+		Pos:            call.Pos(),
+		End:            call.End(),
+		Message:        fmt.Sprintf("%s panics without a deferred %s in the enclosing function", name, k.catcher()),
+		SuggestedFixes: catchFixes(pass, fn, k),
+	})
+}
+
+// Suggests to defer Catch at the start of the function. An unnamed single result is named res.
+func catchFixes(pass *analysis.Pass, fn *function, k kind) []analysis.SuggestedFix {
+	file := enclosingFile(pass, fn.body.Pos())
+	if fn.fixed || fn.typ.Results == nil || len(fn.typ.Results.List) != 1 || len(fn.typ.Results.List[0].Names) > 1 {
+		return nil
+	}
+	field := fn.typ.Results.List[0]
+	typ := pass.TypesInfo.TypeOf(field.Type)
+	catch := ""
+	switch {
+	case k == resultKind && isNamed(typ, resultPath, "Result"):
+		catch = "Catch"
+	case k == resultKind && types.Identical(typ, types.Universe.Lookup("error").Type()):
+		catch = "CatchError"
+	case k == optionKind && isNamed(typ, optionPath, "Option"):
+		catch = "Catch"
+	default:
+		return nil
+	}
+	pkgName := importName(file, map[kind]string{resultKind: resultPath, optionKind: optionPath}[k])
+	if pkgName == "" {
+		return nil
+	}
+
+	var edits []analysis.TextEdit
+	name := ""
+	if len(field.Names) == 1 {
+		name = field.Names[0].Name
+	} else {
+		name = "res"
+		if catch == "CatchError" {
+			name = "err"
+		}
+		if declared(pass, fn, name) {
+			return nil
+		}
+		typeText := string(source(pass, field.Type.Pos(), field.Type.End()))
+		named := name + " " + typeText
+		if !fn.typ.Results.Opening.IsValid() {
+			named = "(" + named + ")"
+		}
+		edits = append(edits, analysis.TextEdit{Pos: field.Type.Pos(), End: field.Type.End(), NewText: []byte(named)})
+	}
+	fn.fixed = true
+	stmt := fmt.Sprintf("defer %s.%s(&%s)", pkgName, catch, name)
+	edits = append(edits, analysis.TextEdit{
+		Pos:     fn.body.Lbrace + 1,
+		End:     fn.body.Lbrace + 1,
+		NewText: []byte("\n" + stmt),
+	})
+	return []analysis.SuggestedFix{{Message: "Add " + stmt, TextEdits: edits}}
+}
+
+func reportNotDeferred(pass *analysis.Pass, fn *function, call *ast.CallExpr, name string) {
+	diag := analysis.Diagnostic{
+		Pos:     call.Pos(),
+		End:     call.End(),
+		Message: fmt.Sprintf("%s must be called directly by a defer statement", name),
+	}
+	stmt := statementOf(fn.body, call)
+	text := "defer " + string(source(pass, call.Pos(), call.End()))
+	switch {
+	case stmt == nil:
+	case fn.deferStmt == nil:
+		// A statement of its own is moved to the start of the function
+		start, end := lineRange(pass, stmt)
+		diag.SuggestedFixes = []analysis.SuggestedFix{{
+			Message: "Defer " + name + " at the start of the function",
+			TextEdits: []analysis.TextEdit{
+				{Pos: fn.body.Lbrace + 1, End: fn.body.Lbrace + 1, NewText: []byte("\n" + text)},
+				{Pos: start, End: end},
+			},
+		}}
+	case len(fn.body.List) == 1:
+		// The deferred function literal is replaced by the direct call
+		diag.SuggestedFixes = []analysis.SuggestedFix{{
+			Message:   "Defer " + name + " directly",
+			TextEdits: []analysis.TextEdit{{Pos: fn.deferStmt.Pos(), End: fn.deferStmt.End(), NewText: []byte(text)}},
+		}}
+	}
+	pass.Report(diag)
+}
+
+func checkPointer(pass *analysis.Pass, fn *function, call *ast.CallExpr, name string) {
+	if len(call.Args) != 1 {
+		return
+	}
+	arg := ast.Unparen(call.Args[0])
+	if unary, ok := arg.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+		if id, ok := ast.Unparen(unary.X).(*ast.Ident); ok && isResult(pass, fn, pass.TypesInfo.Uses[id]) {
+			return
+		}
+	}
+	diag := analysis.Diagnostic{
+		Pos:     arg.Pos(),
+		End:     arg.End(),
+		Message: fmt.Sprintf("%s must point at a named result of the enclosing function", name),
+	}
+	// Points at the only result of the matching type
+	if ptr, ok := pass.TypesInfo.TypeOf(arg).(*types.Pointer); ok && fn.typ.Results != nil {
+		var candidates []string
+		for _, field := range fn.typ.Results.List {
+			for _, id := range field.Names {
+				if types.Identical(pass.TypesInfo.TypeOf(id), ptr.Elem()) {
+					candidates = append(candidates, id.Name)
+				}
+			}
+		}
+		if len(candidates) == 1 {
+			diag.SuggestedFixes = []analysis.SuggestedFix{{
+				Message:   "Point at the result " + candidates[0],
+				TextEdits: []analysis.TextEdit{{Pos: arg.Pos(), End: arg.End(), NewText: []byte("&" + candidates[0])}},
+			}}
+		}
+	}
+	pass.Report(diag)
+}
+
+func isResult(pass *analysis.Pass, fn *function, obj types.Object) bool {
+	if obj == nil || fn.typ.Results == nil {
+		return false
+	}
+	for _, field := range fn.typ.Results.List {
+		for _, id := range field.Names {
+			if pass.TypesInfo.Defs[id] == obj {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isNamed(t types.Type, pkgPath, name string) bool {
+	named, ok := types.Unalias(t).(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == pkgPath && named.Obj().Name() == name
+}
+
+// Reports if the name is declared in the function
+func declared(pass *analysis.Pass, fn *function, name string) bool {
+	found := false
+	ast.Inspect(fn.body, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && id.Name == name && pass.TypesInfo.Defs[id] != nil {
+			found = true
+		}
+		return !found
+	})
+	for _, field := range fn.typ.Params.List {
+		for _, id := range field.Names {
+			found = found || id.Name == name
+		}
+	}
+	return found
+}
+
+// Returns the name under which the file imports the package
+func importName(file *ast.File, path string) string {
+	if file == nil {
+		return ""
+	}
+	for _, spec := range file.Imports {
+		if strings.Trim(spec.Path.Value, `"`) != path {
+			continue
+		}
+		if spec.Name == nil {
+			return path[strings.LastIndex(path, "/")+1:]
+		}
+		if spec.Name.Name != "_" && spec.Name.Name != "." {
+			return spec.Name.Name
+		}
+	}
+	return ""
+}
+
+func enclosingFile(pass *analysis.Pass, pos token.Pos) *ast.File {
+	for _, file := range pass.Files {
+		if file.FileStart <= pos && pos < file.FileEnd {
+			return file
+		}
+	}
+	return nil
+}
+
+// Returns the expression statement of the call if it is a statement of the body
+func statementOf(body *ast.BlockStmt, call *ast.CallExpr) ast.Stmt {
+	var found ast.Stmt
+	ast.Inspect(body, func(n ast.Node) bool {
+		if stmt, ok := n.(*ast.ExprStmt); ok && stmt.X == call {
+			found = stmt
+		}
+		_, isLit := n.(*ast.FuncLit)
+		return found == nil && !isLit
+	})
+	return found
+}
+
+// Returns the range of the lines occupied by the node if nothing else is on them
+func lineRange(pass *analysis.Pass, n ast.Node) (token.Pos, token.Pos) {
+	file := pass.Fset.File(n.Pos())
+	content, err := pass.ReadFile(file.Name())
+	if err != nil {
+		return n.Pos(), n.End()
+	}
+	start, end := file.Offset(n.Pos()), file.Offset(n.End())
+	lineStart := bytes.LastIndexByte(content[:start], '\n') + 1
+	lineEnd := bytes.IndexByte(content[end:], '\n')
+	if lineEnd < 0 || len(bytes.TrimSpace(content[lineStart:start])) > 0 || len(bytes.TrimSpace(content[end:end+lineEnd])) > 0 {
+		return n.Pos(), n.End()
+	}
+	return file.Pos(lineStart), file.Pos(end + lineEnd + 1)
+}
+
+// Returns the source text between the positions
+func source(pass *analysis.Pass, start, end token.Pos) []byte {
+	file := pass.Fset.File(start)
+	content, err := pass.ReadFile(file.Name())
+	if err != nil {
+		return nil
+	}
+	return content[file.Offset(start):file.Offset(end)]
+}
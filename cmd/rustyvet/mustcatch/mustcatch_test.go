@@ -0,0 +1,16 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package mustcatch_test
+
+import (
+	"testing"
+
+	"github.com/pakuula/go-rusty/cmd/rustyvet/mustcatch"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), mustcatch.Analyzer, "./a")
+}
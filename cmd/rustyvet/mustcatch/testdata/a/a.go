@@ -0,0 +1,124 @@
+package a
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/pakuula/go-rusty/option"
+	"github.com/pakuula/go-rusty/result"
+)
+
+func parse(s string) result.Result[int] {
+	return result.Wrap(strconv.Atoi(s))
+}
+
+func Caught(s string) (res result.Result[int]) {
+	defer result.Catch(&res)
+	return result.Val(parse(s).Must() + 1)
+}
+
+func CaughtError(name string) (err error) {
+	defer result.CatchError(&err)
+	result.NoError(os.Remove(name))
+	return nil
+}
+
+func CaughtInClosure(items []string) (res result.Result[int]) {
+	defer result.Catch(&res)
+	sum := 0
+	each(items, func(s string) { sum += parse(s).Must() })
+	return result.Val(sum)
+}
+
+func each(items []string, f func(string)) {
+	for _, item := range items {
+		f(item)
+	}
+}
+
+func Uncaught(s string) result.Result[int] {
+	n := parse(s).Must()          // want `Must panics without a deferred result.Catch in the enclosing function`
+	m := parse(s).Mustf("second") // want `Mustf panics without a deferred result.Catch in the enclosing function`
+	return result.Val(n + m)
+}
+
+func UncaughtError(s string) error {
+	result.Must(strconv.Atoi(s)) // want `Must panics without a deferred result.Catch in the enclosing function`
+	return nil
+}
+
+func UncaughtNoResult(s string) {
+	parse(s).Must() // want `Must panics without a deferred result.Catch in the enclosing function`
+}
+
+func WrongCatcher(m map[string]int) (res option.Option[int]) {
+	defer option.Catch(&res)
+	v := option.MustOk(m["a"], true)
+	return option.Some(v + parse("1").Must()) // want `Must panics without a deferred result.Catch in the enclosing function`
+}
+
+func UncaughtOption(m map[string]int) option.Option[int] {
+	v, ok := m["a"]
+	return option.Some(option.WrapOk(v, ok).Must()) // want `Must panics without a deferred option.Catch in the enclosing function`
+}
+
+func NotDeferred(s string) (res result.Result[int]) {
+	res = result.Val(parse(s).Must()) // want `Must panics without a deferred result.Catch in the enclosing function`
+	result.Catch(&res)                // want `result.Catch must be called directly by a defer statement`
+	return res
+}
+
+func DeferredInClosure(s string) (res result.Result[int]) {
+	defer func() {
+		result.Catch(&res) // want `result.Catch must be called directly by a defer statement`
+	}()
+	return parse(s) // no Must, nothing to catch
+}
+
+func LocalPointer(s string) (n int, err error) {
+	var local error
+	defer result.CatchError(&local) // want `result.CatchError must point at a named result of the enclosing function`
+	return parse(s).Must(), local
+}
+
+func UnnamedResult(s string) result.Result[int] {
+	var res result.Result[int]
+	defer result.Catch(&res) // want `result.Catch must point at a named result of the enclosing function`
+	return result.Val(parse(s).Must())
+}
+
+func Goroutine(items []string) (res result.Result[int]) {
+	defer result.Catch(&res)
+	done := make(chan int)
+	go func() {
+		done <- parse(items[0]).Must() // want `Must in a goroutine is not caught by the enclosing function; defer result.Catch in the goroutine`
+	}()
+	go func() {
+		var res result.Result[int]
+		defer func() { done <- res.UnwrapOr(0) }()
+		defer result.Catch(&res) // want `result.Catch must point at a named result of the enclosing function`
+		res = result.Val(parse(items[1]).Must())
+	}()
+	return result.Val(<-done + <-done)
+}
+
+func DeferredLate(s string) (res result.Result[int]) {
+	n := result.Wrap(strconv.Atoi(s)).Must() // want `Must is not covered by the deferred result.Catch; defer it unconditionally at the start of the function`
+	defer result.Catch(&res)
+	return result.Val(n + parse(s).Must())
+}
+
+func DeferredConditionally(s string, b bool) (res result.Result[int]) {
+	if b {
+		defer result.Catch(&res)
+	}
+	return result.Val(parse(s).Must()) // want `Must is not covered by the deferred result.Catch; defer it unconditionally at the start of the function`
+}
+
+func DeferredLateInClosure(items []string) (res result.Result[int]) {
+	sum := 0
+	f := func(s string) { sum += parse(s).Must() }
+	defer result.Catch(&res)
+	each(items, f)
+	return result.Val(sum)
+}
@@ -0,0 +1,15 @@
+package a
+
+import (
+	"testing"
+
+	"github.com/pakuula/go-rusty/result"
+)
+
+func TestParse(t *testing.T) {
+	if parse("1").Must() != 1 {
+		t.Fail()
+	}
+	var res result.Result[int]
+	defer result.Catch(&res) // want `result.Catch must point at a named result of the enclosing function`
+}
@@ -0,0 +1,223 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+// Package mustuse reports discarded Result and Option values.
+//
+// A call whose result.Result or option.Option value is dropped, assigned to _
+// or lost by a go statement is reported, so is UnwrapUnsafe outside of tests.
+// Deferred calls are not reported, as in defer f.Close(). The report is suppressed by
+// the comment //rusty:ignore on the line of the statement or on the line above.
+// The comment in the doc of a function allows discarding its results everywhere,
+// as does the flag -ignore listing the functions as path.Func or path.Type.Method.
+package mustuse
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const (
+	resultPath = "github.com/pakuula/go-rusty/result"
+	optionPath = "github.com/pakuula/go-rusty/option"
+	directive  = "//rusty:ignore"
+)
+
+var Analyzer = &analysis.Analyzer{
+	Name:      "mustuse",
+	Doc:       "report discarded Result and Option values",
+	Requires:  []*analysis.Analyzer{inspect.Analyzer},
+	Run:       run,
+	FactTypes: []analysis.Fact{new(ignoredFact)},
+}
+
+var ignoreFlag string
+
+func init() {
+	Analyzer.Flags.StringVar(&ignoreFlag, "ignore", "", "comma-separated functions whose results may be discarded, path.Func or path.Type.Method")
+}
+
+// Marks the functions whose doc has the //rusty:ignore comment
+type ignoredFact struct{}
+
+func (*ignoredFact) AFact()         {}
+func (*ignoredFact) String() string { return "ignored" }
+
+func run(pass *analysis.Pass) (any, error) {
+	ignored := map[string]bool{}
+	for _, name := range strings.Split(ignoreFlag, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			ignored[name] = true
+		}
+	}
+
+	// Lines suppressed by the directive, per file
+	lines := map[*token.File]map[int]bool{}
+	for _, file := range pass.Files {
+		tf := pass.Fset.File(file.Pos())
+		lines[tf] = map[int]bool{}
+		for _, group := range file.Comments {
+			for _, c := range group.List {
+				if isDirective(c.Text) {
+					line := tf.Line(c.Pos())
+					lines[tf][line] = true
+					lines[tf][line+1] = true
+				}
+			}
+		}
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Doc != nil && hasDirective(fn.Doc) {
+				if obj, ok := pass.TypesInfo.Defs[fn.Name].(*types.Func); ok {
+					pass.ExportObjectFact(obj, new(ignoredFact))
+				}
+			}
+		}
+	}
+	suppressed := func(n ast.Node) bool {
+		tf := pass.Fset.File(n.Pos())
+		return lines[tf][tf.Line(n.Pos())]
+	}
+
+	// Reports if the value of the call may be discarded
+	allowed := func(call *ast.CallExpr) bool {
+		fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+		if !ok {
+			return false
+		}
+		fn = fn.Origin()
+		// NoError panics on error, its value carries nothing
+		name := funcName(fn)
+		return name == resultPath+".NoError" || pass.ImportObjectFact(fn, new(ignoredFact)) || ignored[name]
+	}
+
+	check := func(stmt ast.Node, call *ast.CallExpr, typ types.Type, assigned bool) {
+		kind := mustUse(typ)
+		if kind == "" || allowed(call) || suppressed(stmt) {
+			return
+		}
+		_, started := stmt.(*ast.GoStmt)
+		switch {
+		case assigned:
+			pass.Reportf(call.Pos(), "%s returned by %s is assigned to _", kind, types.ExprString(call.Fun))
+		case started:
+			pass.Reportf(call.Pos(), "%s returned by %s is lost by the go statement", kind, types.ExprString(call.Fun))
+		default:
+			pass.Reportf(call.Pos(), "unused %s returned by %s", kind, types.ExprString(call.Fun))
+		}
+	}
+
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	nodes := []ast.Node{(*ast.ExprStmt)(nil), (*ast.GoStmt)(nil), (*ast.AssignStmt)(nil), (*ast.ValueSpec)(nil), (*ast.CallExpr)(nil)}
+	inspect.Preorder(nodes, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.ExprStmt:
+			if call, ok := ast.Unparen(n.X).(*ast.CallExpr); ok {
+				check(n, call, pass.TypesInfo.TypeOf(call), false)
+			}
+		case *ast.GoStmt:
+			check(n, n.Call, pass.TypesInfo.TypeOf(n.Call), false)
+		case *ast.AssignStmt:
+			checkBlanks(pass, n, n.Lhs, n.Rhs, check)
+		case *ast.ValueSpec:
+			lhs := make([]ast.Expr, len(n.Names))
+			for i, name := range n.Names {
+				lhs[i] = name
+			}
+			checkBlanks(pass, n, lhs, n.Values, check)
+		case *ast.CallExpr:
+			checkUnsafe(pass, n, suppressed)
+		}
+	})
+	return nil, nil
+}
+
+// Checks the values assigned to _
+func checkBlanks(pass *analysis.Pass, stmt ast.Node, lhs, rhs []ast.Expr,
+	check func(ast.Node, *ast.CallExpr, types.Type, bool)) {
+	for i, left := range lhs {
+		if id, ok := left.(*ast.Ident); !ok || id.Name != "_" {
+			continue
+		}
+		if len(lhs) == len(rhs) {
+			if call, ok := ast.Unparen(rhs[i]).(*ast.CallExpr); ok {
+				check(stmt, call, pass.TypesInfo.TypeOf(call), true)
+			}
+		} else if len(rhs) == 1 {
+			call, ok := ast.Unparen(rhs[0]).(*ast.CallExpr)
+			if !ok {
+				continue
+			}
+			if tuple, ok := pass.TypesInfo.TypeOf(call).(*types.Tuple); ok && i < tuple.Len() {
+				check(stmt, call, tuple.At(i).Type(), true)
+			}
+		}
+	}
+}
+
+func checkUnsafe(pass *analysis.Pass, call *ast.CallExpr, suppressed func(ast.Node) bool) {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok || fn.Name() != "UnwrapUnsafe" {
+		return
+	}
+	name := funcName(fn.Origin())
+	if name != resultPath+".Result.UnwrapUnsafe" && name != optionPath+".Option.UnwrapUnsafe" {
+		return
+	}
+	if strings.HasSuffix(pass.Fset.File(call.Pos()).Name(), "_test.go") || suppressed(call) {
+		return
+	}
+	pass.Reportf(call.Pos(), "UnwrapUnsafe outside of tests, use Unwrap, UnwrapOr or Must")
+}
+
+// Returns "Result" or "Option" if the value of the type must be used
+func mustUse(t types.Type) string {
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok || named.Obj().Pkg() == nil {
+		return ""
+	}
+	switch named.Obj().Pkg().Path() + "." + named.Obj().Name() {
+	case resultPath + ".Result":
+		return "Result"
+	case optionPath + ".Option":
+		return "Option"
+	}
+	return ""
+}
+
+// Returns path.Func or path.Type.Method
+func funcName(fn *types.Func) string {
+	if fn.Pkg() == nil {
+		return fn.Name()
+	}
+	if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+		t := recv.Type()
+		if ptr, ok := t.(*types.Pointer); ok {
+			t = ptr.Elem()
+		}
+		if named, ok := types.Unalias(t).(*types.Named); ok {
+			return fmt.Sprintf("%s.%s.%s", fn.Pkg().Path(), named.Obj().Name(), fn.Name())
+		}
+	}
+	return fn.Pkg().Path() + "." + fn.Name()
+}
+
+func isDirective(text string) bool {
+	return text == directive || strings.HasPrefix(text, directive+" ")
+}
+
+func hasDirective(doc *ast.CommentGroup) bool {
+	for _, c := range doc.List {
+		if isDirective(c.Text) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,18 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package mustuse_test
+
+import (
+	"testing"
+
+	"github.com/pakuula/go-rusty/cmd/rustyvet/mustuse"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	require.NoError(t, mustuse.Analyzer.Flags.Set("ignore", "example.com/rustyvet/b.Quiet"))
+	analysistest.Run(t, analysistest.TestData(), mustuse.Analyzer, "./a")
+}
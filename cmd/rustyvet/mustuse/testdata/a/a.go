@@ -0,0 +1,67 @@
+package a
+
+import (
+	"os"
+
+	"example.com/rustyvet/b"
+	"github.com/pakuula/go-rusty/option"
+	"github.com/pakuula/go-rusty/result"
+	"github.com/pakuula/go-rusty/result/os_r"
+)
+
+func lookup(m map[string]int, key string) option.Option[int] {
+	v, ok := m[key]
+	return option.WrapOk(v, ok)
+}
+
+func pair() (int, result.ResultVoid) {
+	return 0, result.Void(nil)
+}
+
+func Discarded(name string, m map[string]int) {
+	os_r.Remove(name)                  // want `unused Result returned by os_r.Remove`
+	(os_r.Remove(name))                // want `unused Result returned by os_r.Remove`
+	lookup(m, "a")                     // want `unused Option returned by lookup`
+	_ = os_r.Remove(name)              // want `Result returned by os_r.Remove is assigned to _`
+	_, _ = lookup(m, "a"), 1           // want `Option returned by lookup is assigned to _`
+	n, _ := pair()                     // want `Result returned by pair is assigned to _`
+	var _ = result.Wrap(os.Open(name)) // want `Result returned by result.Wrap is assigned to _`
+	f := os_r.WrapFile(os.Stdout)
+	f.WriteString("text") // want `unused Result returned by f.WriteString`
+	go os_r.Remove(name)  // want `Result returned by os_r.Remove is lost by the go statement`
+	go lookup(m, "b")     // want `Option returned by lookup is lost by the go statement`
+	_ = n
+}
+
+func Used(name string, m map[string]int) error {
+	res := os_r.Remove(name)
+	if v := lookup(m, "a"); v.IsSome() {
+		return nil
+	}
+	os.Remove(name)
+	defer os_r.Remove(name)
+	go os.Remove(name)
+	go func() { res.Err() }()
+	result.NoError(os.Remove(name))
+	return res.Err()
+}
+
+func Ignored(name string, c *b.Cache) {
+	os_r.Remove(name) //rusty:ignore best effort
+	//rusty:ignore
+	os_r.Remove(name)
+	go os_r.Remove(name) //rusty:ignore
+	b.Cleanup(name)
+	b.Quiet()
+	c.Evict(name)
+	c.Store(name) // want `unused Result returned by c.Store`
+}
+
+func Unsafe(res result.Result[int], opt option.Option[int]) int {
+	if res.IsError() || opt.IsNone() {
+		return 0
+	}
+	n := res.UnwrapUnsafe() // want `UnwrapUnsafe outside of tests, use Unwrap, UnwrapOr or Must`
+	//rusty:ignore checked above
+	return n + opt.UnwrapUnsafe()
+}
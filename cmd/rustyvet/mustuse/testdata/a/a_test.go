@@ -0,0 +1,13 @@
+package a
+
+import (
+	"testing"
+
+	"github.com/pakuula/go-rusty/result"
+)
+
+func TestUnsafe(t *testing.T) {
+	if result.Val(1).UnwrapUnsafe() != 1 {
+		t.Fail()
+	}
+}
@@ -0,0 +1,26 @@
+package b
+
+import "github.com/pakuula/go-rusty/result"
+
+// Best effort cleanup, the error is not interesting
+//
+//rusty:ignore
+func Cleanup(name string) result.ResultVoid {
+	return result.Void(nil)
+}
+
+// Allowed by the flag -ignore
+func Quiet() result.ResultVoid {
+	return result.Void(nil)
+}
+
+type Cache struct{}
+
+//rusty:ignore
+func (self *Cache) Evict(key string) result.Result[bool] {
+	return result.Val(true)
+}
+
+func (self *Cache) Store(key string) result.ResultVoid {
+	return result.Void(nil)
+}
@@ -0,0 +1,7 @@
+module example.com/rustyvet
+
+go 1.21.3
+
+require github.com/pakuula/go-rusty v0.0.0
+
+replace github.com/pakuula/go-rusty => ../../../..
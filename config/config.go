@@ -0,0 +1,357 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+// Package config loads a struct from environment variables, JSON files and flag sets.
+//
+// The fields are described by tags:
+//
+//	type Config struct {
+//		Port    int                          `env:"PORT" flag:"port" json:"port" default:"8080"`
+//		Timeout option.Option[time.Duration] `env:"TIMEOUT"`
+//		Token   string                       `env:"TOKEN" required:"true"`
+//		DB      struct {
+//			Host string `env:"DB_HOST" json:"host"`
+//		} `json:"db"`
+//	}
+//
+// Fields of the type option.Option[T] are None if no source provides a value
+// or if the JSON value is null. For the other fields null is ignored like in encoding/json.
+// As in encoding/json, the fields of embedded structs without a `json` tag are promoted
+// and the fields tagged `json:"-"` are not read from JSON.
+// The fields tagged `env:"-"` are not read from the environment.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pakuula/go-rusty/internal/parse"
+	"github.com/pakuula/go-rusty/option"
+	"github.com/pakuula/go-rusty/result"
+	"github.com/pakuula/go-rusty/result/os_r"
+	"github.com/pakuula/go-rusty/validated"
+)
+
+var ErrNotStruct = errors.New("config is not a struct")
+var ErrMissing = errors.New("required value is missing")
+
+// A raw value found by a source: JSON if json is not nil,
+// the value of a flag.Getter if get is not nil, text otherwise
+type value struct {
+	text   string
+	json   json.RawMessage
+	get    any
+	origin string
+}
+
+// The description of a struct field built from its tags
+type field struct {
+	path     string
+	env      option.Option[string]
+	flag     option.Option[string]
+	json     []string // empty for json:"-"
+	def      option.Option[string]
+	required bool
+}
+
+// A source of configuration values
+type Source interface {
+	prepare() result.ResultVoid
+	lookup(f *field) option.Option[value]
+}
+
+// Environment variables
+
+type envSource struct {
+	prefix string
+}
+
+// Reads the variables named by the `env` tags, prepended with the prefix
+func Env(prefix string) Source {
+	return envSource{prefix: prefix}
+}
+
+func (self envSource) prepare() result.ResultVoid {
+	return result.Void(nil)
+}
+
+func (self envSource) lookup(f *field) option.Option[value] {
+	return option.ApplyOption(f.env, func(name string) option.Option[value] {
+		name = self.prefix + name
+		return option.Apply(os_r.LookupEnv(name), func(text string) value {
+			return value{text: text, origin: "env " + name}
+		})
+	})
+}
+
+// JSON documents
+
+type jsonSource struct {
+	name string
+	read func() result.Result[[]byte]
+	doc  map[string]json.RawMessage
+}
+
+// Reads the JSON file. The fields are matched by the `json` tags or the field names.
+func JsonFile(path string) Source {
+	return &jsonSource{name: path, read: func() result.Result[[]byte] { return os_r.ReadFile(path) }}
+}
+
+// Reads the JSON document. The fields are matched by the `json` tags or the field names.
+func Json(data []byte) Source {
+	return &jsonSource{name: "json", read: func() result.Result[[]byte] { return result.Val(data) }}
+}
+
+func (self *jsonSource) prepare() result.ResultVoid {
+	doc := result.ApplyResult(self.read(), result.UnmarshalJson[map[string]json.RawMessage])
+	if doc.IsError() {
+		return result.Void(fmt.Errorf("%s: %w", self.name, doc.Err()))
+	}
+	self.doc = doc.Unwrap()
+	return result.Void(nil)
+}
+
+func lookupKey(doc map[string]json.RawMessage, key string) option.Option[json.RawMessage] {
+	if raw, ok := doc[key]; ok {
+		return option.Some(raw)
+	}
+	for k, raw := range doc {
+		if strings.EqualFold(k, key) {
+			return option.Some(raw)
+		}
+	}
+	return option.None[json.RawMessage]()
+}
+
+func (self *jsonSource) lookup(f *field) (res option.Option[value]) {
+	if len(f.json) == 0 {
+		return option.None[value]()
+	}
+	defer option.Catch(&res)
+	doc := self.doc
+	last := len(f.json) - 1
+	for _, key := range f.json[:last] {
+		raw := lookupKey(doc, key).Must()
+		doc = result.UnmarshalJson[map[string]json.RawMessage](raw).UnwrapOrDefault()
+	}
+	raw := lookupKey(doc, f.json[last]).Must()
+	return option.Some(value{json: raw, origin: self.name})
+}
+
+// Flag sets
+
+type flagSource struct {
+	fs *flag.FlagSet
+}
+
+// Reads the flags named by the `flag` tags.
+// Only the flags set on the command line are taken, so Parse must be called before Load.
+// The value of a flag.Getter, such as a slice of a custom flag, is assigned directly
+// if it fits the field, other flags are parsed from their string representation.
+func Flags(fs *flag.FlagSet) Source {
+	return flagSource{fs: fs}
+}
+
+func (self flagSource) prepare() result.ResultVoid {
+	if !self.fs.Parsed() {
+		return result.Void(fmt.Errorf("flag set %s is not parsed", self.fs.Name()))
+	}
+	return result.Void(nil)
+}
+
+func (self flagSource) lookup(f *field) option.Option[value] {
+	return option.ApplyOption(f.flag, func(name string) option.Option[value] {
+		found := option.None[value]()
+		self.fs.Visit(func(fl *flag.Flag) {
+			if fl.Name == name {
+				val := value{text: fl.Value.String(), origin: "flag -" + name}
+				if getter, ok := fl.Value.(flag.Getter); ok {
+					val.get = getter.Get()
+				}
+				found = option.Some(val)
+			}
+		})
+		return found
+	})
+}
+
+// Loading
+
+var optionPkg = reflect.TypeOf(option.None[int]()).PkgPath()
+
+// Returns T for the type option.Option[T]
+func optionOf(t reflect.Type) option.Option[reflect.Type] {
+	if t.PkgPath() != optionPkg || !strings.HasPrefix(t.Name(), "Option[") {
+		return option.None[reflect.Type]()
+	}
+	replace, _ := reflect.PointerTo(t).MethodByName("Replace")
+	return option.Some(replace.Type.In(1))
+}
+
+// True if the fields of the struct are filled one by one
+func nested(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !parse.Supported(t) && optionOf(t).IsNone()
+}
+
+// Returns the key of the field in JSON documents and false for json:"-"
+func jsonName(sf reflect.StructField) (string, bool) {
+	tag := sf.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name, true
+	}
+	return sf.Name, true
+}
+
+func describe(sf reflect.StructField, parent *field) *field {
+	f := &field{path: sf.Name}
+	name, ok := jsonName(sf)
+	switch {
+	case parent == nil && ok:
+		f.json = []string{name}
+	case parent != nil && ok && len(parent.json) != 0:
+		f.json = append(append([]string{}, parent.json...), name)
+	}
+	if parent != nil {
+		f.path = parent.path + "." + sf.Name
+	}
+	env, ok := sf.Tag.Lookup("env")
+	f.env = option.WrapOk(env, ok && env != "-")
+	f.flag = option.WrapOk(sf.Tag.Lookup("flag"))
+	f.def = option.WrapOk(sf.Tag.Lookup("default"))
+	f.required = sf.Tag.Get("required") == "true"
+	return f
+}
+
+// Loads the struct T. Later sources override earlier ones:
+//
+//	config.Load[Config](config.JsonFile("config.json"), config.Env("APP_"), config.Flags(flag.CommandLine))
+//
+// The `default` tag is used if no source provides a value.
+// The error lists every field that failed, see validated.Errors.
+func Load[T any](sources ...Source) result.Result[T] {
+	var cfg T
+	v := reflect.ValueOf(&cfg).Elem()
+	if v.Kind() != reflect.Struct {
+		return result.Err[T](ErrNotStruct)
+	}
+	var errs validated.Errors
+	for _, s := range sources {
+		if res := s.prepare(); res.IsError() {
+			errs = append(errs, validated.FieldError{Err: res.Err()})
+		}
+	}
+	if len(errs) == 0 {
+		errs = fill(v, nil, sources)
+	}
+	if len(errs) != 0 {
+		return validated.InvalidErrors[T](errs).ToResult()
+	}
+	return result.Val(cfg)
+}
+
+func fill(v reflect.Value, parent *field, sources []Source) validated.Errors {
+	var errs validated.Errors
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		fv := v.Field(i)
+		// Promoted like in encoding/json
+		if sf.Anonymous && nested(sf.Type) && sf.Tag.Get("json") == "" {
+			errs = append(errs, fill(fv, parent, sources)...)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		f := describe(sf, parent)
+		if nested(sf.Type) {
+			errs = append(errs, fill(fv, f, sources)...)
+			continue
+		}
+		if err := set(fv, f, sources); err != nil {
+			errs = append(errs, validated.FieldError{Field: f.path, Err: err})
+		}
+	}
+	return errs
+}
+
+// Sets the field from the last source providing a value.
+// The value of an Option[T] field is parsed into T and stored by Replace.
+func set(fv reflect.Value, f *field, sources []Source) error {
+	dst := fv
+	isOption := false
+	if elem := optionOf(fv.Type()); elem.IsSome() {
+		dst = reflect.New(elem.Unwrap()).Elem()
+		isOption = true
+	}
+	store := func() {
+		if isOption {
+			fv.Addr().MethodByName("Replace").Call([]reflect.Value{dst})
+		}
+	}
+	for i := len(sources) - 1; i >= 0; i-- {
+		found := sources[i].lookup(f)
+		if found.IsNone() {
+			continue
+		}
+		val := found.Unwrap()
+		var err error
+		switch {
+		case val.json != nil && isNull(val.json) && !isOption:
+			// Like encoding/json, null leaves the field as the other sources set it
+			continue
+		case val.json != nil && isNull(val.json):
+			fv.Addr().MethodByName("Take").Call(nil)
+			return nil
+		case val.json != nil:
+			err = setJson(dst, val.json)
+		case val.get != nil && reflect.TypeOf(val.get).AssignableTo(fv.Type()):
+			fv.Set(reflect.ValueOf(val.get))
+			return nil
+		case val.get != nil && reflect.TypeOf(val.get).AssignableTo(dst.Type()):
+			dst.Set(reflect.ValueOf(val.get))
+		default:
+			err = parse.Into(val.text, dst)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", val.origin, err)
+		}
+		store()
+		return nil
+	}
+	switch {
+	case f.def.IsSome():
+		if err := parse.Into(f.def.Unwrap(), dst); err != nil {
+			return fmt.Errorf("default: %w", err)
+		}
+		store()
+	case f.required:
+		return ErrMissing
+	case isOption:
+		fv.Addr().MethodByName("Take").Call(nil)
+	}
+	return nil
+}
+
+func isNull(raw json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+}
+
+// Decodes the JSON value. A JSON string is parsed like the text of the other sources,
+// so that "5s" is accepted for time.Duration.
+func setJson(fv reflect.Value, raw json.RawMessage) error {
+	var text string
+	if fv.Kind() != reflect.String && parse.Supported(fv.Type()) && json.Unmarshal(raw, &text) == nil {
+		return parse.Into(text, fv)
+	}
+	return json.Unmarshal(raw, fv.Addr().Interface())
+}
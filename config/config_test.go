@@ -0,0 +1,211 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package config_test
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pakuula/go-rusty/config"
+	"github.com/pakuula/go-rusty/option"
+	"github.com/pakuula/go-rusty/result/os_r"
+	"github.com/pakuula/go-rusty/validated"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type DB struct {
+	Host string `env:"DB_HOST" json:"host" default:"localhost"`
+	Port int    `env:"DB_PORT" json:"port"`
+}
+
+type Config struct {
+	Port    int                          `env:"PORT" flag:"port" json:"port" default:"8080"`
+	Debug   bool                         `env:"DEBUG" flag:"debug"`
+	Timeout option.Option[time.Duration] `env:"TIMEOUT" json:"timeout"`
+	Retries option.Option[int]           `env:"RETRIES" json:"retries"`
+	Token   string                       `env:"TOKEN" required:"true"`
+	DB      DB                           `json:"db"`
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"port": 9000, "retries": 3, "db": {"port": 5432}}`), 0o600))
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Int("port", 0, "port")
+	fs.Bool("debug", false, "debug")
+	require.NoError(t, fs.Parse([]string{"-debug"}))
+
+	os_r.WithEnv(map[string]string{"APP_TOKEN": "secret", "APP_DB_HOST": "db.local"}, func() {
+		cfg := config.Load[Config](config.JsonFile(path), config.Env("APP_"), config.Flags(fs)).Unwrap()
+		assert.Equal(t, 9000, cfg.Port)
+		assert.True(t, cfg.Debug)
+		assert.True(t, cfg.Timeout.IsNone())
+		assert.Equal(t, 3, cfg.Retries.Unwrap())
+		assert.Equal(t, "secret", cfg.Token)
+		assert.Equal(t, DB{Host: "db.local", Port: 5432}, cfg.DB)
+	}).Must()
+}
+
+func TestPriority(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Int("port", 0, "port")
+	require.NoError(t, fs.Parse([]string{"-port", "1"}))
+
+	os_r.WithEnv(map[string]string{"PORT": "2", "TOKEN": "t", "TIMEOUT": "5s"}, func() {
+		cfg := config.Load[Config](config.Json([]byte(`{"port": 3}`)), config.Env(""), config.Flags(fs)).Unwrap()
+		assert.Equal(t, 1, cfg.Port)
+		assert.Equal(t, 5*time.Second, cfg.Timeout.Unwrap())
+
+		cfg = config.Load[Config](config.Env(""), config.Json([]byte(`{"port": 3}`))).Unwrap()
+		assert.Equal(t, 3, cfg.Port)
+
+		cfg = config.Load[Config](config.Env("X_"), config.Json([]byte(`{"token": "t"}`))).Unwrap()
+		assert.Equal(t, 8080, cfg.Port)
+	}).Must()
+}
+
+func TestErrors(t *testing.T) {
+	os_r.WithEnv(map[string]string{"PORT": "eighty", "RETRIES": "many", "DB_PORT": "x"}, func() {
+		res := config.Load[Config](config.Env(""))
+		require.True(t, res.IsError())
+
+		var errs validated.Errors
+		require.True(t, errors.As(res.Err(), &errs))
+		fields := []string{}
+		for _, e := range errs {
+			fields = append(fields, e.Field)
+		}
+		assert.Equal(t, []string{"Port", "Retries", "Token", "DB.Port"}, fields)
+		assert.ErrorIs(t, res.Err(), config.ErrMissing)
+		assert.ErrorIs(t, res.Err(), strconv.ErrSyntax)
+		assert.Contains(t, res.Err().Error(), "Port: env PORT: ")
+	}).Must()
+
+	assert.True(t, config.Load[Config](config.JsonFile("/nonexistent/config.json")).IsError())
+	assert.True(t, config.Load[Config](config.Json([]byte(`[`))).IsError())
+	assert.ErrorIs(t, config.Load[int]().Err(), config.ErrNotStruct)
+
+	unparsed := flag.NewFlagSet("test", flag.ContinueOnError)
+	assert.True(t, config.Load[Config](config.Flags(unparsed)).IsError())
+}
+
+type names []string
+
+func (self *names) String() string { return strings.Join(*self, ",") }
+
+func (self *names) Set(s string) error {
+	*self = append(*self, s)
+	return nil
+}
+
+func (self *names) Get() any { return []string(*self) }
+
+type Common struct {
+	Name string `env:"NAME" json:"name"`
+}
+
+type Service struct {
+	Common
+	Secret  string                       `env:"SECRET" json:"-"`
+	Backup  string                       `env:"-" json:"backup"`
+	Timeout time.Duration                `env:"TIMEOUT" json:"timeout"`
+	Delay   option.Option[time.Duration] `json:"delay"`
+	Key     option.Option[string]        `env:"KEY" required:"true"`
+	Hosts   []string                     `flag:"host"`
+	Tags    option.Option[[]string]      `flag:"tag"`
+}
+
+func TestJsonSkip(t *testing.T) {
+	doc := []byte(`{"name": "svc", "Secret": "leaked", "-": "leaked", "timeout": "5s", "delay": "1m", "key": "k"}`)
+	cfg := config.Load[Service](config.Json(doc)).Unwrap()
+	assert.Empty(t, cfg.Secret)
+
+	os_r.WithEnv(map[string]string{"SECRET": "s"}, func() {
+		assert.Equal(t, "s", config.Load[Service](config.Json(doc), config.Env("")).Unwrap().Secret)
+	}).Must()
+}
+
+func TestEnvSkip(t *testing.T) {
+	os_r.WithEnv(map[string]string{"BACKUP": "env", "-": "env", "KEY": "k"}, func() {
+		cfg := config.Load[Service](config.Json([]byte(`{"backup": "json", "key": "k"}`)), config.Env("")).Unwrap()
+		assert.Equal(t, "json", cfg.Backup)
+		assert.Empty(t, config.Load[Service](config.Env("")).Unwrap().Backup)
+	}).Must()
+}
+
+type Nullable struct {
+	Port    int                          `json:"port" default:"8080"`
+	Timeout option.Option[time.Duration] `json:"timeout"`
+	Name    option.Option[string]        `json:"name"`
+}
+
+func TestJsonNull(t *testing.T) {
+	null := config.Json([]byte(`{"port": null, "timeout": null, "name": null}`))
+	cfg := config.Load[Nullable](null).Unwrap()
+	assert.Equal(t, 8080, cfg.Port)
+	assert.True(t, cfg.Timeout.IsNone())
+	assert.True(t, cfg.Name.IsNone())
+
+	cfg = config.Load[Nullable](config.Json([]byte(`{"port": 9000, "timeout": "5s", "name": "svc"}`)), null).Unwrap()
+	assert.Equal(t, 9000, cfg.Port)
+	assert.True(t, cfg.Timeout.IsNone())
+	assert.True(t, cfg.Name.IsNone())
+}
+
+func TestRequiredOption(t *testing.T) {
+	res := config.Load[Service](config.Json([]byte(`{}`)))
+	assert.ErrorIs(t, res.Err(), config.ErrMissing)
+	assert.ErrorContains(t, res.Err(), "Key: ")
+
+	cfg := config.Load[Service](config.Json([]byte(`{"key": "k"}`))).Unwrap()
+	assert.Equal(t, "k", cfg.Key.Unwrap())
+	assert.True(t, cfg.Delay.IsNone())
+}
+
+func TestJsonDuration(t *testing.T) {
+	cfg := config.Load[Service](config.Json([]byte(`{"timeout": "5s", "delay": "1m", "key": "k"}`))).Unwrap()
+	assert.Equal(t, 5*time.Second, cfg.Timeout)
+	assert.Equal(t, time.Minute, cfg.Delay.Unwrap())
+
+	cfg = config.Load[Service](config.Json([]byte(`{"timeout": 1000, "key": "k"}`))).Unwrap()
+	assert.Equal(t, time.Microsecond, cfg.Timeout)
+
+	res := config.Load[Service](config.Json([]byte(`{"timeout": "soon", "key": "k"}`)))
+	assert.ErrorContains(t, res.Err(), "Timeout: json: ")
+}
+
+func TestEmbedded(t *testing.T) {
+	cfg := config.Load[Service](config.Json([]byte(`{"name": "svc", "key": "k"}`))).Unwrap()
+	assert.Equal(t, "svc", cfg.Name)
+
+	os_r.WithEnv(map[string]string{"NAME": "env", "KEY": "k"}, func() {
+		assert.Equal(t, "env", config.Load[Service](config.Env("")).Unwrap().Name)
+	}).Must()
+
+	res := config.Load[Service](config.Json([]byte(`{"name": 1, "key": "k"}`)))
+	assert.ErrorContains(t, res.Err(), "Name: json: ")
+}
+
+func TestSliceFlags(t *testing.T) {
+	var hosts names
+	var tags option.Option[[]string]
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(&hosts, "host", "host")
+	option.FlagVar(fs, &tags, "tag", "tag")
+	require.NoError(t, fs.Parse([]string{"-host", "a,b", "-host", "c", "-tag", "x", "-tag", "y"}))
+
+	cfg := config.Load[Service](config.Json([]byte(`{"key": "k"}`)), config.Flags(fs)).Unwrap()
+	assert.Equal(t, []string{"a,b", "c"}, cfg.Hosts)
+	assert.Equal(t, []string{"x", "y"}, cfg.Tags.Unwrap())
+}
@@ -0,0 +1,233 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package either
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/pakuula/go-rusty/option"
+	"github.com/pakuula/go-rusty/result"
+)
+
+// A value of the type L or a value of the type R
+type Either[L any, R any] struct {
+	left    L
+	right   R
+	isRight bool
+}
+
+// Constructors
+
+func Left[L any, R any](value L) Either[L, R] {
+	return Either[L, R]{left: value}
+}
+
+func Right[L any, R any](value R) Either[L, R] {
+	return Either[L, R]{right: value, isRight: true}
+}
+
+// Converts Result[T] into Either: the error goes left, the value goes right
+func FromResult[T any](res result.Result[T]) Either[error, T] {
+	if res.IsError() {
+		return Left[error, T](res.Err())
+	}
+	return Right[error](res.Unwrap())
+}
+
+// Converts Either into Result[T]: Left becomes the error, Right becomes the value.
+//
+// Panics if the left error is nil.
+func ToResult[T any](e Either[error, T]) result.Result[T] {
+	if e.isRight {
+		return result.Val(e.right)
+	}
+	return result.Err[T](e.left)
+}
+
+// String representaion
+
+// Builds a string representation of the Either object: "Left(...)" or "Right(...)"
+func (self Either[L, R]) String() string {
+	if self.isRight {
+		return fmt.Sprintf("Right(%v)", self.right)
+	}
+	return fmt.Sprintf("Left(%v)", self.left)
+}
+
+// Check the Either
+
+// True if self holds the left value
+func (self Either[L, R]) IsLeft() bool {
+	return !self.isRight
+}
+
+// True if self holds the right value
+func (self Either[L, R]) IsRight() bool {
+	return self.isRight
+}
+
+// True if self holds the left value and it matches the condition
+func (self Either[L, R]) IsLeftAnd(cond func(L) bool) bool {
+	return !self.isRight && cond(self.left)
+}
+
+// True if self holds the right value and it matches the condition
+func (self Either[L, R]) IsRightAnd(cond func(R) bool) bool {
+	return self.isRight && cond(self.right)
+}
+
+// Extracting the stored value
+
+var ErrUnwrapLeft = errors.New("unwrapping left of right")
+var ErrUnwrapRight = errors.New("unwrapping right of left")
+
+// Returns the left value as Option
+func (self Either[L, R]) Left() option.Option[L] {
+	if self.isRight {
+		return option.None[L]()
+	}
+	return option.Some(self.left)
+}
+
+// Returns the right value as Option
+func (self Either[L, R]) Right() option.Option[R] {
+	if !self.isRight {
+		return option.None[R]()
+	}
+	return option.Some(self.right)
+}
+
+// Returns the left value or panics
+func (self Either[L, R]) UnwrapLeft() L {
+	if self.isRight {
+		panic(ErrUnwrapLeft)
+	}
+	return self.left
+}
+
+// Returns the right value or panics
+func (self Either[L, R]) UnwrapRight() R {
+	if !self.isRight {
+		panic(ErrUnwrapRight)
+	}
+	return self.right
+}
+
+// Returns the left value or the provided default value
+func (self Either[L, R]) UnwrapLeftOr(valueIfRight L) L {
+	if self.isRight {
+		return valueIfRight
+	}
+	return self.left
+}
+
+// Returns the right value or the provided default value
+func (self Either[L, R]) UnwrapRightOr(valueIfLeft R) R {
+	if !self.isRight {
+		return valueIfLeft
+	}
+	return self.right
+}
+
+// Converts to the triple (left, right, isRight)
+func (self Either[L, R]) UnwrapWithSide() (L, R, bool) {
+	return self.left, self.right, self.isRight
+}
+
+// Transform the Either
+
+// Swaps the sides
+func (self Either[L, R]) Flip() Either[R, L] {
+	return Either[R, L]{left: self.right, right: self.left, isRight: !self.isRight}
+}
+
+// Calls onLeft or onRight depending on the stored side
+func Match[L any, R any, U any](e Either[L, R], onLeft func(L) U, onRight func(R) U) U {
+	if e.isRight {
+		return onRight(e.right)
+	}
+	return onLeft(e.left)
+}
+
+// Applies f to the left value or keeps the right value unchanged
+func MapLeft[L any, R any, U any](e Either[L, R], f func(L) U) Either[U, R] {
+	if e.isRight {
+		return Right[U](e.right)
+	}
+	return Left[U, R](f(e.left))
+}
+
+// Applies f to the right value or keeps the left value unchanged
+func MapRight[L any, R any, U any](e Either[L, R], f func(R) U) Either[L, U] {
+	if !e.isRight {
+		return Left[L, U](e.left)
+	}
+	return Right[L](f(e.right))
+}
+
+// JSON
+
+type jsonEither[L any, R any] struct {
+	Left  *L `json:"left,omitempty"`
+	Right *R `json:"right,omitempty"`
+}
+
+// Encodes the Either as {"left": value} or {"right": value}
+func (self Either[L, R]) MarshalJSON() ([]byte, error) {
+	if self.isRight {
+		return json.Marshal(jsonEither[L, R]{Right: &self.right})
+	}
+	return json.Marshal(jsonEither[L, R]{Left: &self.left})
+}
+
+var ErrInvalidJson = errors.New(`either: expected exactly one of "left" or "right"`)
+
+// Decodes the Either from {"left": value} or {"right": value}.
+// Like encoding/json, null leaves the Either unchanged.
+func (self *Either[L, R]) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	left, hasLeft := raw["left"]
+	right, hasRight := raw["right"]
+	if len(raw) != 1 || hasLeft == hasRight {
+		return ErrInvalidJson
+	}
+	var decoded Either[L, R]
+	if hasRight {
+		decoded.isRight = true
+		if err := json.Unmarshal(right, &decoded.right); err != nil {
+			return err
+		}
+	} else {
+		if err := json.Unmarshal(left, &decoded.left); err != nil {
+			return err
+		}
+	}
+	*self = decoded
+	return nil
+}
+
+// Slices
+
+// Splits a slice of Either values into the left and the right values
+func Partition[L any, R any](slice []Either[L, R]) ([]L, []R) {
+	var lefts []L
+	var rights []R
+	for _, e := range slice {
+		if e.isRight {
+			rights = append(rights, e.right)
+		} else {
+			lefts = append(lefts, e.left)
+		}
+	}
+	return lefts, rights
+}
@@ -0,0 +1,88 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package either_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/pakuula/go-rusty/either"
+	"github.com/pakuula/go-rusty/result"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type TE = either.Either[string, int]
+
+var errTest = errors.New("test error")
+
+func TestCTOR(t *testing.T) {
+	{
+		l := either.Left[string, int]("left")
+		assert.True(t, l.IsLeft())
+		assert.False(t, l.IsRight())
+		assert.Equal(t, "left", l.UnwrapLeft())
+		assert.Panics(t, func() { l.UnwrapRight() })
+		assert.Equal(t, 5, l.UnwrapRightOr(5))
+		assert.True(t, l.Right().IsNone())
+		assert.Equal(t, "Left(left)", l.String())
+	}
+	{
+		r := either.Right[string](1)
+		assert.False(t, r.IsLeft())
+		assert.True(t, r.IsRight())
+		assert.Equal(t, 1, r.UnwrapRight())
+		assert.Panics(t, func() { r.UnwrapLeft() })
+		assert.Equal(t, "x", r.UnwrapLeftOr("x"))
+		assert.True(t, r.Left().IsNone())
+		assert.Equal(t, "Right(1)", r.String())
+		assert.True(t, r.Flip().IsLeft())
+	}
+}
+
+func TestMatch(t *testing.T) {
+	toString := func(e TE) string {
+		return either.Match(e,
+			func(s string) string { return s },
+			func(i int) string { return strconv.Itoa(i) },
+		)
+	}
+	assert.Equal(t, "a", toString(either.Left[string, int]("a")))
+	assert.Equal(t, "2", toString(either.Right[string](2)))
+
+	doubled := either.MapRight(either.Right[string](2), func(i int) int { return i * 2 })
+	assert.Equal(t, 4, doubled.UnwrapRight())
+	length := either.MapLeft(either.Left[string, int]("abc"), func(s string) int { return len(s) })
+	assert.Equal(t, 3, length.UnwrapLeft())
+}
+
+func TestResult(t *testing.T) {
+	e := either.FromResult(result.Err[int](errTest))
+	assert.True(t, e.IsLeft())
+	assert.Equal(t, errTest, either.ToResult(e).Err())
+
+	v := either.FromResult(result.Val(1))
+	assert.True(t, v.IsRight())
+	assert.Equal(t, 1, either.ToResult(v).Unwrap())
+}
+
+func TestJSON(t *testing.T) {
+	for _, e := range []TE{either.Left[string, int]("a"), either.Right[string](1)} {
+		bz, err := json.Marshal(e)
+		require.NoError(t, err)
+		var decoded TE
+		require.NoError(t, json.Unmarshal(bz, &decoded))
+		assert.Equal(t, e, decoded)
+	}
+	var decoded TE
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"left":"a","right":1}`), &decoded), either.ErrInvalidJson)
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{}`), &decoded), either.ErrInvalidJson)
+
+	decoded = either.Right[string](1)
+	require.NoError(t, json.Unmarshal([]byte(`null`), &decoded))
+	assert.Equal(t, either.Right[string](1), decoded)
+}
@@ -0,0 +1,118 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package expr
+
+import (
+	"github.com/pakuula/go-rusty/option"
+	"github.com/pakuula/go-rusty/result"
+)
+
+// A branch of the Cond expression
+type Branch[T any] struct {
+	cond  bool
+	value func() T
+}
+
+// Builds a branch that is taken if cond is true
+func When[T any](cond bool, Then func() T) Branch[T] {
+	return Branch[T]{cond: cond, value: Then}
+}
+
+// Builds a branch that is always taken
+func Otherwise[T any](Else func() T) Branch[T] {
+	return Branch[T]{cond: true, value: Else}
+}
+
+// Evaluates the first branch whose condition is true.
+// Returns the zero value if no branch is taken.
+func Cond[T any](branches ...Branch[T]) T {
+	for _, b := range branches {
+		if b.cond {
+			return b.value()
+		}
+	}
+	return Default[T]()
+}
+
+// Matches a value against the cases of the Switch expression
+type Switcher[V comparable, T any] struct {
+	value   V
+	matched bool
+	result  T
+}
+
+// Starts the Switch expression over the value v.
+// The type of the result has to be specified: Switch[string](code)
+func Switch[T any, V comparable](v V) Switcher[V, T] {
+	return Switcher[V, T]{value: v}
+}
+
+// Selects Then if the value equals to the case
+func (self Switcher[V, T]) Case(c V, Then T) Switcher[V, T] {
+	if self.matched || self.value != c {
+		return self
+	}
+	return Switcher[V, T]{value: self.value, matched: true, result: Then}
+}
+
+// Evaluates Then if the value equals to the case
+func (self Switcher[V, T]) CaseLazy(c V, Then func() T) Switcher[V, T] {
+	if self.matched || self.value != c {
+		return self
+	}
+	return Switcher[V, T]{value: self.value, matched: true, result: Then()}
+}
+
+// Returns the value of the matched case or Else
+func (self Switcher[V, T]) Default(Else T) T {
+	if self.matched {
+		return self.result
+	}
+	return Else
+}
+
+// Returns the value of the matched case or evaluates Else
+func (self Switcher[V, T]) DefaultLazy(Else func() T) T {
+	if self.matched {
+		return self.result
+	}
+	return Else()
+}
+
+// Returns the value of the matched case or None
+func (self Switcher[V, T]) Option() option.Option[T] {
+	return option.WrapOk(self.result, self.matched)
+}
+
+// Returns the first non-zero value or the zero value
+func Coalesce[T comparable](vals ...T) T {
+	var zero T
+	for _, v := range vals {
+		if v != zero {
+			return v
+		}
+	}
+	return zero
+}
+
+// Returns the first Some or None
+func CoalesceOpt[T any](opts ...option.Option[T]) option.Option[T] {
+	for _, opt := range opts {
+		if opt.IsSome() {
+			return opt
+		}
+	}
+	return option.None[T]()
+}
+
+// Evaluates Then with the value of the option or Else if the option is None
+func IfSome[T any, U any](opt option.Option[T], Then func(T) U, Else func() U) U {
+	return option.Match(opt, Then, Else)
+}
+
+// Evaluates Then with the value of the result or Else with its error
+func IfOk[T any, U any](res result.Result[T], Then func(T) U, Else func(error) U) U {
+	return result.Match(res, Then, Else)
+}
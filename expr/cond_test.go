@@ -0,0 +1,63 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package expr_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pakuula/go-rusty/expr"
+	"github.com/pakuula/go-rusty/option"
+	"github.com/pakuula/go-rusty/result"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCond(t *testing.T) {
+	evaluated := 0
+	branch := func(s string) func() string {
+		return func() string { evaluated++; return s }
+	}
+	grade := func(score int) string {
+		return expr.Cond(
+			expr.When(score >= 90, branch("A")),
+			expr.When(score >= 75, branch("B")),
+			expr.Otherwise(branch("C")),
+		)
+	}
+	assert.Equal(t, "A", grade(95))
+	assert.Equal(t, "B", grade(80))
+	assert.Equal(t, "C", grade(10))
+	assert.Equal(t, 3, evaluated)
+	assert.Equal(t, "", expr.Cond(expr.When(false, branch("X"))))
+}
+
+func TestSwitch(t *testing.T) {
+	status := func(code int) string {
+		return expr.Switch[string](code).
+			Case(200, "ok").
+			Case(404, "not found").
+			Default("unknown")
+	}
+	assert.Equal(t, "ok", status(200))
+	assert.Equal(t, "not found", status(404))
+	assert.Equal(t, "unknown", status(500))
+	assert.True(t, expr.Switch[string](1).Case(2, "two").Option().IsNone())
+	assert.Equal(t, "one", expr.Switch[string](1).CaseLazy(1, func() string { return "one" }).Default(""))
+}
+
+func TestCoalesce(t *testing.T) {
+	assert.Equal(t, "b", expr.Coalesce("", "b", "c"))
+	assert.Equal(t, 0, expr.Coalesce(0, 0))
+	assert.Equal(t, 2, expr.CoalesceOpt(option.None[int](), option.Some(2), option.Some(3)).Unwrap())
+	assert.True(t, expr.CoalesceOpt[int]().IsNone())
+}
+
+func TestIfSomeIfOk(t *testing.T) {
+	double := func(v int) int { return v * 2 }
+	assert.Equal(t, 4, expr.IfSome(option.Some(2), double, func() int { return -1 }))
+	assert.Equal(t, -1, expr.IfSome(option.None[int](), double, func() int { return -1 }))
+	assert.Equal(t, 4, expr.IfOk(result.Val(2), double, func(error) int { return -1 }))
+	assert.Equal(t, -1, expr.IfOk(result.Err[int](errors.New("e")), double, func(error) int { return -1 }))
+}
@@ -0,0 +1,34 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package expr
+
+import "sync"
+
+// A value computed on the first access.
+// It is safe to call Get from multiple goroutines.
+type Lazy[T any] struct {
+	mu    sync.Mutex
+	f     func() T
+	done  bool
+	value T
+}
+
+// Builds a Lazy object that calls f on the first Get
+func NewLazy[T any](f func() T) *Lazy[T] {
+	return &Lazy[T]{f: f}
+}
+
+// Returns the computed value, computing it if needed.
+// If f panics, the panic is passed to the caller and the next Get calls f again.
+func (self *Lazy[T]) Get() T {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	if !self.done {
+		self.value = self.f()
+		self.done = true
+		self.f = nil
+	}
+	return self.value
+}
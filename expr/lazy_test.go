@@ -0,0 +1,36 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package expr_test
+
+import (
+	"testing"
+
+	"github.com/pakuula/go-rusty/expr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLazy(t *testing.T) {
+	calls := 0
+	lazy := expr.NewLazy(func() int { calls++; return 42 })
+	assert.Equal(t, 0, calls)
+	assert.Equal(t, 42, lazy.Get())
+	assert.Equal(t, 42, lazy.Get())
+	assert.Equal(t, 1, calls)
+}
+
+func TestLazyPanic(t *testing.T) {
+	calls := 0
+	lazy := expr.NewLazy(func() int {
+		calls++
+		if calls == 1 {
+			panic("first call")
+		}
+		return 42
+	})
+	assert.PanicsWithValue(t, "first call", func() { lazy.Get() })
+	assert.Equal(t, 42, lazy.Get())
+	assert.Equal(t, 42, lazy.Get())
+	assert.Equal(t, 2, calls)
+}
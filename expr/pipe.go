@@ -0,0 +1,20 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package expr
+
+// Passes the value through the functions: Pipe(x, f, g) == g(f(x))
+func Pipe[T any](x T, fs ...func(T) T) T {
+	for _, f := range fs {
+		x = f(x)
+	}
+	return x
+}
+
+// Combines the functions into a single function
+func Compose[T any](fs ...func(T) T) func(T) T {
+	return func(x T) T {
+		return Pipe(x, fs...)
+	}
+}
@@ -0,0 +1,20 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package expr_test
+
+import (
+	"testing"
+
+	"github.com/pakuula/go-rusty/expr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipe(t *testing.T) {
+	inc := func(v int) int { return v + 1 }
+	double := func(v int) int { return v * 2 }
+	assert.Equal(t, 4, expr.Pipe(1, inc, double))
+	assert.Equal(t, 3, expr.Compose(double, inc)(1))
+	assert.Equal(t, 1, expr.Pipe(1))
+}
@@ -0,0 +1,133 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+// Package parse converts strings into scalar values.
+// It is shared by the packages that parse text: strings_o, regexp_o, os_r, option, config.
+package parse
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+var urlType = reflect.TypeOf(url.URL{})
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// True if values of the type can be parsed by Into
+func Supported(t reflect.Type) bool {
+	if t == urlType || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Bool, reflect.String:
+		return true
+	case reflect.Pointer:
+		return Supported(t.Elem())
+	}
+	return false
+}
+
+// Parses s into the settable value dst.
+//
+// Supported are the types implementing encoding.TextUnmarshaler, time.Duration,
+// url.URL, integers, floats, booleans, strings and pointers to them.
+// Integers are decimal unless they have one of the prefixes 0x, 0o or 0b,
+// so "010" is 10.
+func Into(s string, dst reflect.Value) error {
+	if dst.CanAddr() {
+		if u, ok := dst.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(s))
+		}
+	}
+	if dst.Type() == urlType {
+		u, err := url.Parse(s)
+		if err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(*u))
+		return nil
+	}
+	if dst.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		dst.SetInt(int64(d))
+		return nil
+	}
+	switch dst.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		digits, base := integer(s)
+		v, err := strconv.ParseInt(digits, base, dst.Type().Bits())
+		if err != nil {
+			return err
+		}
+		dst.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		digits, base := integer(s)
+		v, err := strconv.ParseUint(digits, base, dst.Type().Bits())
+		if err != nil {
+			return err
+		}
+		dst.SetUint(v)
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(s, dst.Type().Bits())
+		if err != nil {
+			return err
+		}
+		dst.SetFloat(v)
+	case reflect.Bool:
+		v, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		dst.SetBool(v)
+	case reflect.String:
+		dst.SetString(s)
+	case reflect.Pointer:
+		elem := reflect.New(dst.Type().Elem())
+		if err := Into(s, elem.Elem()); err != nil {
+			return err
+		}
+		dst.Set(elem)
+	default:
+		return fmt.Errorf("parsing %s is not supported", dst.Type())
+	}
+	return nil
+}
+
+// Strips the base prefix from the integer keeping the sign.
+// Base 0 of strconv is not used: it reads "010" as octal and accepts underscores.
+func integer(s string) (string, int) {
+	sign, digits := "", s
+	if s != "" && (s[0] == '+' || s[0] == '-') {
+		sign, digits = s[:1], s[1:]
+	}
+	// The sign after the prefix is an error, base 10 rejects it
+	if len(digits) > 2 && digits[0] == '0' && digits[2] != '+' && digits[2] != '-' {
+		switch digits[1] {
+		case 'x', 'X':
+			return sign + digits[2:], 16
+		case 'o', 'O':
+			return sign + digits[2:], 8
+		case 'b', 'B':
+			return sign + digits[2:], 2
+		}
+	}
+	return s, 10
+}
+
+// Parses s into a value of the type T
+func As[T any](s string) (T, error) {
+	var value T
+	err := Into(s, reflect.ValueOf(&value).Elem())
+	return value, err
+}
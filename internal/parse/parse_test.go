@@ -0,0 +1,58 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package parse_test
+
+import (
+	"net/netip"
+	"reflect"
+	"testing"
+
+	"github.com/pakuula/go-rusty/internal/parse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAs(t *testing.T) {
+	addr, err := parse.As[netip.Addr]("127.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, netip.MustParseAddr("127.0.0.1"), addr)
+
+	ptr, err := parse.As[*int]("5")
+	require.NoError(t, err)
+	assert.Equal(t, 5, *ptr)
+
+	_, err = parse.As[[]int]("1")
+	assert.Error(t, err)
+}
+
+func TestIntegers(t *testing.T) {
+	for s, expected := range map[string]int{
+		"010": 10, "-010": -10, "+7": 7, "0x1f": 31, "-0X10": -16, "0o17": 15, "0b101": 5, "0": 0,
+	} {
+		v, err := parse.As[int](s)
+		require.NoError(t, err, s)
+		assert.Equal(t, expected, v, s)
+	}
+	for _, s := range []string{"1_000", "0x", "0x-1", "08x", ""} {
+		_, err := parse.As[int](s)
+		assert.Error(t, err, s)
+	}
+
+	u, err := parse.As[uint16]("0xffff")
+	require.NoError(t, err)
+	assert.Equal(t, uint16(0xffff), u)
+	u, err = parse.As[uint16]("0010")
+	require.NoError(t, err)
+	assert.Equal(t, uint16(10), u)
+	_, err = parse.As[uint16]("1_0")
+	assert.Error(t, err)
+}
+
+func TestSupported(t *testing.T) {
+	assert.True(t, parse.Supported(reflect.TypeOf(netip.Addr{})))
+	assert.True(t, parse.Supported(reflect.TypeOf(new(float32))))
+	assert.False(t, parse.Supported(reflect.TypeOf([]int{})))
+	assert.False(t, parse.Supported(reflect.TypeOf(struct{}{})))
+}
@@ -0,0 +1,237 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package num
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+
+	"github.com/pakuula/go-rusty/option"
+	"github.com/pakuula/go-rusty/result"
+)
+
+// Constraints, the same as in golang.org/x/exp/constraints
+
+type Signed interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64
+}
+
+type Unsigned interface {
+	~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
+type Integer interface {
+	Signed | Unsigned
+}
+
+// Properties of the type
+
+func bits[T Integer]() uint {
+	var zero T
+	return uint(unsafe.Sizeof(zero)) * 8
+}
+
+func isSigned[T Integer]() bool {
+	return minusOne[T]() < 0
+}
+
+// -1 for signed types, the maximal value for unsigned ones
+func minusOne[T Integer]() T {
+	var zero T
+	return zero - 1
+}
+
+// Returns the minimal value of the type T
+func MinOf[T Integer]() T {
+	if !isSigned[T]() {
+		return 0
+	}
+	return T(1) << (bits[T]() - 1)
+}
+
+// Returns the maximal value of the type T
+func MaxOf[T Integer]() T {
+	if !isSigned[T]() {
+		return ^T(0)
+	}
+	return MinOf[T]() - 1
+}
+
+// Overflowing arithmetic: the wrapped result and true if overflow occurred
+
+func OverflowingAdd[T Integer](a, b T) (T, bool) {
+	r := a + b
+	if isSigned[T]() {
+		return r, (b > 0 && r < a) || (b < 0 && r > a)
+	}
+	return r, r < a
+}
+
+func OverflowingSub[T Integer](a, b T) (T, bool) {
+	r := a - b
+	if isSigned[T]() {
+		return r, (b > 0 && r > a) || (b < 0 && r < a)
+	}
+	return r, a < b
+}
+
+func OverflowingMul[T Integer](a, b T) (T, bool) {
+	r := a * b
+	if a == 0 || b == 0 {
+		return r, false
+	}
+	if isSigned[T]() && ((a == minusOne[T]() && b == MinOf[T]()) || (b == minusOne[T]() && a == MinOf[T]())) {
+		return r, true
+	}
+	return r, r/b != a
+}
+
+func OverflowingNeg[T Integer](a T) (T, bool) {
+	if isSigned[T]() {
+		return -a, a == MinOf[T]()
+	}
+	return -a, a != 0
+}
+
+// Overflows only for MinOf[T]() / -1, that wraps to MinOf[T](). Panics on division by zero.
+func OverflowingDiv[T Integer](a, b T) (T, bool) {
+	return a / b, isSigned[T]() && a == MinOf[T]() && b == minusOne[T]()
+}
+
+// Shifts by n modulo the number of bits of the type,
+// overflows if n is not less than the number of bits
+func OverflowingShl[T Integer](a T, n uint) (T, bool) {
+	return WrappingShl(a, n), n >= bits[T]()
+}
+
+// Wrapping arithmetic: the result wraps around at the boundary of the type
+
+func WrappingAdd[T Integer](a, b T) T { return a + b }
+func WrappingSub[T Integer](a, b T) T { return a - b }
+func WrappingMul[T Integer](a, b T) T { return a * b }
+func WrappingNeg[T Integer](a T) T    { return -a }
+
+// Wraps MinOf[T]() / -1 to MinOf[T](). Panics on division by zero.
+func WrappingDiv[T Integer](a, b T) T { return a / b }
+
+// Shifts by n modulo the number of bits of the type
+func WrappingShl[T Integer](a T, n uint) T { return a << (n % bits[T]()) }
+
+// Checked arithmetic: None on overflow or division by zero
+
+func CheckedAdd[T Integer](a, b T) option.Option[T] {
+	r, overflow := OverflowingAdd(a, b)
+	return option.WrapOk(r, !overflow)
+}
+
+func CheckedSub[T Integer](a, b T) option.Option[T] {
+	r, overflow := OverflowingSub(a, b)
+	return option.WrapOk(r, !overflow)
+}
+
+func CheckedMul[T Integer](a, b T) option.Option[T] {
+	r, overflow := OverflowingMul(a, b)
+	return option.WrapOk(r, !overflow)
+}
+
+func CheckedDiv[T Integer](a, b T) option.Option[T] {
+	if b == 0 || (isSigned[T]() && a == MinOf[T]() && b == minusOne[T]()) {
+		return option.None[T]()
+	}
+	return option.Some(a / b)
+}
+
+func CheckedRem[T Integer](a, b T) option.Option[T] {
+	if b == 0 || (isSigned[T]() && a == MinOf[T]() && b == minusOne[T]()) {
+		return option.None[T]()
+	}
+	return option.Some(a % b)
+}
+
+func CheckedNeg[T Integer](a T) option.Option[T] {
+	r, overflow := OverflowingNeg(a)
+	return option.WrapOk(r, !overflow)
+}
+
+// Returns None if n is not less than the number of bits of the type
+func CheckedShl[T Integer](a T, n uint) option.Option[T] {
+	if n >= bits[T]() {
+		return option.None[T]()
+	}
+	return option.Some(a << n)
+}
+
+// Saturating arithmetic: the result sticks to the boundary of the type
+
+func SaturatingAdd[T Integer](a, b T) T {
+	r, overflow := OverflowingAdd(a, b)
+	switch {
+	case !overflow:
+		return r
+	case b < 0:
+		return MinOf[T]()
+	default:
+		return MaxOf[T]()
+	}
+}
+
+func SaturatingSub[T Integer](a, b T) T {
+	r, overflow := OverflowingSub(a, b)
+	switch {
+	case !overflow:
+		return r
+	case b > 0:
+		return MinOf[T]()
+	default:
+		return MaxOf[T]()
+	}
+}
+
+func SaturatingMul[T Integer](a, b T) T {
+	r, overflow := OverflowingMul(a, b)
+	switch {
+	case !overflow:
+		return r
+	case (a < 0) != (b < 0):
+		return MinOf[T]()
+	default:
+		return MaxOf[T]()
+	}
+}
+
+// Conversions
+
+var ErrOutOfRange = errors.New("value out of range")
+
+// The value can't be converted to the target type without loss
+type ConversionError struct {
+	Value string
+	From  string
+	To    string
+}
+
+func (self *ConversionError) Error() string {
+	return fmt.Sprintf("cannot convert %s from %s to %s: %s", self.Value, self.From, self.To, ErrOutOfRange)
+}
+
+func (self *ConversionError) Unwrap() error {
+	return ErrOutOfRange
+}
+
+// Converts the integer to the type To.
+// Returns *ConversionError if the value does not fit into To.
+func TryConvert[From Integer, To Integer](v From) result.Result[To] {
+	t := To(v)
+	if From(t) != v || (v < 0) != (t < 0) {
+		var zero To
+		return result.Err[To](&ConversionError{
+			Value: fmt.Sprint(v),
+			From:  fmt.Sprintf("%T", v),
+			To:    fmt.Sprintf("%T", zero),
+		})
+	}
+	return result.Val(t)
+}
@@ -0,0 +1,117 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package num_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/pakuula/go-rusty/num"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBounds(t *testing.T) {
+	assert.Equal(t, int8(math.MinInt8), num.MinOf[int8]())
+	assert.Equal(t, int8(math.MaxInt8), num.MaxOf[int8]())
+	assert.Equal(t, int64(math.MinInt64), num.MinOf[int64]())
+	assert.Equal(t, uint16(0), num.MinOf[uint16]())
+	assert.Equal(t, uint16(math.MaxUint16), num.MaxOf[uint16]())
+}
+
+func clamp(v, lo, hi int) int {
+	return min(max(v, lo), hi)
+}
+
+// Compares the results for all pairs of int8 and uint8 against int arithmetic
+func checkAll[T int8 | uint8](t *testing.T) {
+	lo, hi := int(num.MinOf[T]()), int(num.MaxOf[T]())
+	inRange := func(v int) bool { return v >= lo && v <= hi }
+	for x := lo; x <= hi; x++ {
+		a := T(x)
+		neg := num.CheckedNeg(a)
+		require.Equal(t, inRange(-x), neg.IsSome(), "neg %d", x)
+		for y := lo; y <= hi; y++ {
+			b := T(y)
+			for _, c := range []struct {
+				name     string
+				exact    int
+				checked  func(T, T) (T, bool)
+				saturate func(T, T) T
+			}{
+				{"add", x + y, func(a, b T) (T, bool) { return num.CheckedAdd(a, b).UnwrapWithOk() }, num.SaturatingAdd[T]},
+				{"sub", x - y, func(a, b T) (T, bool) { return num.CheckedSub(a, b).UnwrapWithOk() }, num.SaturatingSub[T]},
+				{"mul", x * y, func(a, b T) (T, bool) { return num.CheckedMul(a, b).UnwrapWithOk() }, num.SaturatingMul[T]},
+			} {
+				r, ok := c.checked(a, b)
+				require.Equal(t, inRange(c.exact), ok, "%s %d %d", c.name, x, y)
+				if ok {
+					require.Equal(t, T(c.exact), r, "%s %d %d", c.name, x, y)
+				}
+				require.Equal(t, T(clamp(c.exact, lo, hi)), c.saturate(a, b), "saturating %s %d %d", c.name, x, y)
+			}
+			div := num.CheckedDiv(a, b)
+			if y == 0 {
+				require.True(t, div.IsNone())
+			} else {
+				require.Equal(t, inRange(x/y), div.IsSome(), "div %d %d", x, y)
+				r, overflow := num.OverflowingDiv(a, b)
+				require.Equal(t, !inRange(x/y), overflow, "overflowing div %d %d", x, y)
+				require.Equal(t, num.WrappingDiv(a, b), r, "overflowing div %d %d", x, y)
+			}
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	checkAll[int8](t)
+	checkAll[uint8](t)
+}
+
+func TestWrapping(t *testing.T) {
+	assert.Equal(t, int8(math.MinInt8), num.WrappingAdd[int8](math.MaxInt8, 1))
+	assert.Equal(t, uint8(255), num.WrappingSub[uint8](0, 1))
+	assert.Equal(t, int8(math.MinInt8), num.WrappingNeg[int8](math.MinInt8))
+	assert.Equal(t, int8(math.MinInt8), num.WrappingDiv[int8](math.MinInt8, -1))
+	assert.Equal(t, uint8(2), num.WrappingShl[uint8](1, 9))
+
+	r, overflow := num.OverflowingMul[int32](math.MaxInt32, 2)
+	assert.True(t, overflow)
+	assert.Equal(t, int32(-2), r)
+
+	r, overflow = num.OverflowingDiv[int32](math.MinInt32, -1)
+	assert.True(t, overflow)
+	assert.Equal(t, int32(math.MinInt32), r)
+	r, overflow = num.OverflowingDiv[int32](7, -2)
+	assert.False(t, overflow)
+	assert.Equal(t, int32(-3), r)
+	assert.Panics(t, func() { num.OverflowingDiv[int32](1, 0) })
+
+	u, overflow := num.OverflowingShl[uint8](1, 9)
+	assert.True(t, overflow)
+	assert.Equal(t, uint8(2), u)
+	u, overflow = num.OverflowingShl[uint8](1, 7)
+	assert.False(t, overflow)
+	assert.Equal(t, uint8(128), u)
+}
+
+func TestShl(t *testing.T) {
+	assert.Equal(t, uint8(128), num.CheckedShl[uint8](1, 7).Unwrap())
+	assert.True(t, num.CheckedShl[uint8](1, 8).IsNone())
+}
+
+func TestTryConvert(t *testing.T) {
+	assert.Equal(t, uint8(200), num.TryConvert[int, uint8](200).Unwrap())
+	assert.Equal(t, int64(-1), num.TryConvert[int8, int64](-1).Unwrap())
+
+	res := num.TryConvert[int, uint8](300)
+	assert.ErrorIs(t, res.Err(), num.ErrOutOfRange)
+	var convErr *num.ConversionError
+	require.ErrorAs(t, res.Err(), &convErr)
+	assert.Equal(t, num.ConversionError{Value: "300", From: "int", To: "uint8"}, *convErr)
+
+	assert.True(t, num.TryConvert[int8, uint64](-1).IsError())
+	assert.True(t, num.TryConvert[uint64, int64](math.MaxUint64).IsError())
+}
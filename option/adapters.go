@@ -0,0 +1,115 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package option
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+)
+
+// Type assertion
+
+// Converts v to the type T. Returns None if v is not of the type T.
+func Cast[T any](v any) Option[T] {
+	t, ok := v.(T)
+	return WrapOk(t, ok)
+}
+
+// sync.Map access
+
+// Loads the value from sync.Map. Returns None if the key is missing
+// or the stored value is not of the type V.
+func SyncMapLoad[K any, V any](m *sync.Map, key K) Option[V] {
+	v, ok := m.Load(key)
+	if !ok {
+		return None[V]()
+	}
+	return Cast[V](v)
+}
+
+// Slice access
+
+// Returns the i-th element of the slice or None if i is out of range
+func Index[T any](slice []T, i int) Option[T] {
+	if i < 0 || i >= len(slice) {
+		return None[T]()
+	}
+	return Some(slice[i])
+}
+
+// Returns the first element of the slice or None if the slice is empty
+func First[T any](slice []T) Option[T] {
+	return Index(slice, 0)
+}
+
+// Returns the last element of the slice or None if the slice is empty
+func Last[T any](slice []T) Option[T] {
+	return Index(slice, len(slice)-1)
+}
+
+// Removes the last element of the slice and returns it.
+// Returns None if the slice is empty.
+func Pop[T any](slice *[]T) Option[T] {
+	n := len(*slice)
+	if n == 0 {
+		return None[T]()
+	}
+	last := (*slice)[n-1]
+	var zero T
+	(*slice)[n-1] = zero
+	*slice = (*slice)[:n-1]
+	return Some(last)
+}
+
+// Slice search
+
+// Returns the first element that matches the condition
+func Find[T any](slice []T, cond func(T) bool) Option[T] {
+	for _, v := range slice {
+		if cond(v) {
+			return Some(v)
+		}
+	}
+	return None[T]()
+}
+
+// Returns the index of the first element that matches the condition
+func FindIndex[T any](slice []T, cond func(T) bool) Option[int] {
+	i := slices.IndexFunc(slice, cond)
+	return WrapOk(i, i >= 0)
+}
+
+// Searches the target in the sorted slice and returns its index
+func BinarySearch[T cmp.Ordered](sorted []T, target T) Option[int] {
+	return WrapOk(slices.BinarySearch(sorted, target))
+}
+
+// Returns the first minimal element according to the comparison function
+func MinBy[T any](slice []T, compare func(a, b T) int) Option[T] {
+	if len(slice) == 0 {
+		return None[T]()
+	}
+	return Some(slices.MinFunc(slice, compare))
+}
+
+// Returns the first maximal element according to the comparison function
+func MaxBy[T any](slice []T, compare func(a, b T) int) Option[T] {
+	if len(slice) == 0 {
+		return None[T]()
+	}
+	return Some(slices.MaxFunc(slice, compare))
+}
+
+// Applies f to every element and keeps the values of the Some results
+func FilterMap[T any, U any](slice []T, f func(T) Option[U]) []U {
+	var retval []U
+	for _, v := range slice {
+		if u := f(v); u.IsSome() {
+			retval = append(retval, u.value)
+		}
+	}
+	return retval
+}
@@ -0,0 +1,64 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package option_test
+
+import (
+	"cmp"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/pakuula/go-rusty/option"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCast(t *testing.T) {
+	var v any = 1
+	assert.Equal(t, 1, option.Cast[int](v).Unwrap())
+	assert.True(t, option.Cast[string](v).IsNone())
+	assert.True(t, option.Cast[int](nil).IsNone())
+
+	var m sync.Map
+	m.Store("a", 1)
+	assert.Equal(t, 1, option.SyncMapLoad[string, int](&m, "a").Unwrap())
+	assert.True(t, option.SyncMapLoad[string, string](&m, "a").IsNone())
+	assert.True(t, option.SyncMapLoad[string, int](&m, "b").IsNone())
+}
+
+func TestSliceAccess(t *testing.T) {
+	slice := []int{1, 2, 3}
+	assert.Equal(t, 2, option.Index(slice, 1).Unwrap())
+	assert.True(t, option.Index(slice, 3).IsNone())
+	assert.True(t, option.Index(slice, -1).IsNone())
+	assert.Equal(t, 1, option.First(slice).Unwrap())
+	assert.Equal(t, 3, option.Last(slice).Unwrap())
+	assert.True(t, option.Last([]int{}).IsNone())
+
+	assert.Equal(t, 3, option.Pop(&slice).Unwrap())
+	assert.Equal(t, []int{1, 2}, slice)
+	assert.Equal(t, 2, option.Pop(&slice).Unwrap())
+	assert.Equal(t, 1, option.Pop(&slice).Unwrap())
+	assert.True(t, option.Pop(&slice).IsNone())
+}
+
+func TestSliceSearch(t *testing.T) {
+	slice := []int{5, 1, 4, 1, 9}
+	even := func(v int) bool { return v%2 == 0 }
+	assert.Equal(t, 4, option.Find(slice, even).Unwrap())
+	assert.Equal(t, 2, option.FindIndex(slice, even).Unwrap())
+	assert.True(t, option.FindIndex([]int{1, 3}, even).IsNone())
+
+	assert.Equal(t, 2, option.BinarySearch([]int{1, 3, 5}, 5).Unwrap())
+	assert.True(t, option.BinarySearch([]int{1, 3, 5}, 4).IsNone())
+
+	assert.Equal(t, 1, option.MinBy(slice, cmp.Compare[int]).Unwrap())
+	assert.Equal(t, 9, option.MaxBy(slice, cmp.Compare[int]).Unwrap())
+	assert.True(t, option.MinBy([]int{}, cmp.Compare[int]).IsNone())
+
+	parsed := option.FilterMap([]string{"1", "x", "3"}, func(s string) option.Option[int] {
+		return option.WrapErr(strconv.Atoi(s))
+	})
+	assert.Equal(t, []int{1, 3}, parsed)
+}
@@ -0,0 +1,81 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package bytes_o
+
+import (
+	"bytes"
+	"unicode/utf8"
+
+	"github.com/pakuula/go-rusty/option"
+	"github.com/pakuula/go-rusty/option/strings_o"
+	"github.com/pakuula/go-rusty/result"
+	"github.com/pakuula/go-rusty/tuple"
+)
+
+// Splits b around the first instance of sep.
+// Returns None if sep is not found.
+func SplitOnce(b, sep []byte) option.Option[tuple.Pair[[]byte, []byte]] {
+	before, after, found := bytes.Cut(b, sep)
+	return option.WrapOk(tuple.NewPair(before, after), found)
+}
+
+// Splits b around the last instance of sep.
+// Returns None if sep is not found.
+func RSplitOnce(b, sep []byte) option.Option[tuple.Pair[[]byte, []byte]] {
+	i := bytes.LastIndex(b, sep)
+	if i < 0 {
+		return option.None[tuple.Pair[[]byte, []byte]]()
+	}
+	return option.Some(tuple.NewPair(b[:i], b[i+len(sep):]))
+}
+
+// Returns b without the prefix or None if b does not start with it
+func StripPrefix(b, prefix []byte) option.Option[[]byte] {
+	return option.WrapOk(bytes.CutPrefix(b, prefix))
+}
+
+// Returns b without the suffix or None if b does not end with it
+func StripSuffix(b, suffix []byte) option.Option[[]byte] {
+	return option.WrapOk(bytes.CutSuffix(b, suffix))
+}
+
+// Returns the index of the first instance of sub or None
+func Find(b, sub []byte) option.Option[int] {
+	i := bytes.Index(b, sub)
+	return option.WrapOk(i, i >= 0)
+}
+
+// Returns the index of the last instance of sub or None
+func RFind(b, sub []byte) option.Option[int] {
+	i := bytes.LastIndex(b, sub)
+	return option.WrapOk(i, i >= 0)
+}
+
+// Returns the i-th byte of b or None if b is shorter
+func ByteAt(b []byte, i int) option.Option[byte] {
+	return option.Index(b, i)
+}
+
+// Returns the i-th rune of UTF-8 encoded b or None if b is shorter.
+// Note that i counts runes, not bytes.
+func CharAt(b []byte, i int) option.Option[rune] {
+	if i < 0 {
+		return option.None[rune]()
+	}
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		if i == 0 {
+			return option.Some(r)
+		}
+		i--
+		b = b[size:]
+	}
+	return option.None[rune]()
+}
+
+// Parses b into a number, a boolean or a time.Duration
+func ParseAs[T strings_o.Parsable](b []byte) result.Result[T] {
+	return strings_o.ParseAs[T](string(b))
+}
@@ -0,0 +1,32 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package bytes_o_test
+
+import (
+	"testing"
+
+	"github.com/pakuula/go-rusty/option/bytes_o"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBytes(t *testing.T) {
+	pair := bytes_o.SplitOnce([]byte("k=v"), []byte("=")).Unwrap()
+	assert.Equal(t, []byte("k"), pair.First)
+	assert.Equal(t, []byte("v"), pair.Second)
+	assert.True(t, bytes_o.RSplitOnce([]byte("kv"), []byte("=")).IsNone())
+
+	assert.Equal(t, []byte("bar"), bytes_o.StripPrefix([]byte("foobar"), []byte("foo")).Unwrap())
+	assert.True(t, bytes_o.StripSuffix([]byte("foobar"), []byte("foo")).IsNone())
+
+	assert.Equal(t, 3, bytes_o.Find([]byte("foobar"), []byte("bar")).Unwrap())
+	assert.True(t, bytes_o.RFind([]byte("foobar"), []byte("x")).IsNone())
+
+	assert.Equal(t, byte('o'), bytes_o.ByteAt([]byte("foo"), 1).Unwrap())
+	assert.Equal(t, 'ж', bytes_o.CharAt([]byte("ёж"), 1).Unwrap())
+	assert.True(t, bytes_o.CharAt([]byte("ёж"), 2).IsNone())
+
+	assert.Equal(t, 42, bytes_o.ParseAs[int]([]byte("42")).Unwrap())
+	assert.True(t, bytes_o.ParseAs[bool]([]byte("maybe")).IsError())
+}
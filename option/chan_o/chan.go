@@ -0,0 +1,102 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package chan_o
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/pakuula/go-rusty/option"
+	"github.com/pakuula/go-rusty/result"
+)
+
+var ErrClosed = errors.New("channel is closed")
+var ErrTimeout = errors.New("channel operation timed out")
+
+// Receives a value. Returns None if the channel is closed.
+func Recv[T any](ch <-chan T) option.Option[T] {
+	v, ok := <-ch
+	return option.WrapOk(v, ok)
+}
+
+// Receives a value without blocking.
+// Returns None if the channel is closed or has no value ready.
+func TryRecv[T any](ch <-chan T) option.Option[T] {
+	select {
+	case v, ok := <-ch:
+		return option.WrapOk(v, ok)
+	default:
+		return option.None[T]()
+	}
+}
+
+// Receives a value or returns the context error if the context is done first.
+// Returns ErrClosed if the channel is closed.
+func RecvCtx[T any](ctx context.Context, ch <-chan T) result.Result[T] {
+	if err := ctx.Err(); err != nil {
+		return result.Err[T](err)
+	}
+	select {
+	case v, ok := <-ch:
+		if !ok {
+			return result.Err[T](ErrClosed)
+		}
+		return result.Val(v)
+	case <-ctx.Done():
+		return result.Err[T](ctx.Err())
+	}
+}
+
+// Receives a value or returns ErrTimeout if none arrives within the timeout.
+// Returns ErrClosed if the channel is closed.
+func RecvTimeout[T any](ch <-chan T, timeout time.Duration) result.Result[T] {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case v, ok := <-ch:
+		if !ok {
+			return result.Err[T](ErrClosed)
+		}
+		return result.Val(v)
+	case <-timer.C:
+		return result.Err[T](ErrTimeout)
+	}
+}
+
+// Sends the value without blocking. Returns false if the channel is not ready.
+func TrySend[T any](ch chan<- T, v T) bool {
+	select {
+	case ch <- v:
+		return true
+	default:
+		return false
+	}
+}
+
+// Sends the value or returns the context error if the context is done first
+func SendCtx[T any](ctx context.Context, ch chan<- T, v T) result.ResultVoid {
+	if err := ctx.Err(); err != nil {
+		return result.Void(err)
+	}
+	select {
+	case ch <- v:
+		return result.Void(nil)
+	case <-ctx.Done():
+		return result.Void(ctx.Err())
+	}
+}
+
+// Sends the value or returns ErrTimeout if the channel is not ready within the timeout
+func SendTimeout[T any](ch chan<- T, v T, timeout time.Duration) result.ResultVoid {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case ch <- v:
+		return result.Void(nil)
+	case <-timer.C:
+		return result.Void(ErrTimeout)
+	}
+}
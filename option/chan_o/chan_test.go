@@ -0,0 +1,79 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package chan_o_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pakuula/go-rusty/option/chan_o"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecv(t *testing.T) {
+	ch := make(chan int, 1)
+	ch <- 1
+	assert.Equal(t, 1, chan_o.Recv(ch).Unwrap())
+	close(ch)
+	assert.True(t, chan_o.Recv(ch).IsNone())
+}
+
+func TestTryRecvSend(t *testing.T) {
+	ch := make(chan int, 1)
+	assert.True(t, chan_o.TryRecv(ch).IsNone())
+	assert.True(t, chan_o.TrySend(ch, 1))
+	assert.False(t, chan_o.TrySend(ch, 2))
+	assert.Equal(t, 1, chan_o.TryRecv(ch).Unwrap())
+}
+
+func TestRecvCtx(t *testing.T) {
+	ch := make(chan int)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		chan_o.SendCtx(ctx, ch, 1).Must()
+	}()
+	assert.Equal(t, 1, chan_o.RecvCtx(ctx, ch).Unwrap())
+
+	cancel()
+	assert.ErrorIs(t, chan_o.RecvCtx(ctx, ch).Err(), context.Canceled)
+	assert.ErrorIs(t, chan_o.SendCtx(ctx, ch, 2).Err(), context.Canceled)
+
+	close(ch)
+	assert.ErrorIs(t, chan_o.RecvCtx(context.Background(), ch).Err(), chan_o.ErrClosed)
+}
+
+func TestTimeout(t *testing.T) {
+	ch := make(chan int)
+	assert.ErrorIs(t, chan_o.RecvTimeout(ch, time.Millisecond).Err(), chan_o.ErrTimeout)
+	assert.ErrorIs(t, chan_o.SendTimeout(ch, 1, time.Millisecond).Err(), chan_o.ErrTimeout)
+
+	go func() { ch <- 1 }()
+	assert.Equal(t, 1, chan_o.RecvTimeout(ch, time.Second).Unwrap())
+	close(ch)
+	assert.ErrorIs(t, chan_o.RecvTimeout(ch, time.Second).Err(), chan_o.ErrClosed)
+}
+
+func TestConcurrent(t *testing.T) {
+	ch := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			chan_o.SendCtx(context.Background(), ch, i).Must()
+		}(i)
+	}
+	go func() {
+		wg.Wait()
+		close(ch)
+	}()
+	sum := 0
+	for v := chan_o.Recv(ch); v.IsSome(); v = chan_o.Recv(ch) {
+		sum += v.Unwrap()
+	}
+	assert.Equal(t, 45, sum)
+}
@@ -0,0 +1,50 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+//go:build go1.23
+
+package chan_o
+
+import (
+	"context"
+	"iter"
+)
+
+// Iterates over the values received from the channel until it is closed
+func Seq[T any](ch <-chan T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range ch {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Iterates over the values received from the channel until it is closed or the context is done
+func SeqCtx[T any](ctx context.Context, ch <-chan T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			res := RecvCtx(ctx, ch)
+			if res.IsError() || !yield(res.Unwrap()) {
+				return
+			}
+		}
+	}
+}
+
+// Sends the values of the sequence to a new channel from a goroutine.
+// The channel is closed when the sequence ends or the context is done.
+func FromSeq[T any](ctx context.Context, seq iter.Seq[T]) <-chan T {
+	ch := make(chan T)
+	go func() {
+		defer close(ch)
+		for v := range seq {
+			if SendCtx(ctx, ch, v).IsError() {
+				return
+			}
+		}
+	}()
+	return ch
+}
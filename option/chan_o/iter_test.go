@@ -0,0 +1,47 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+//go:build go1.23
+
+package chan_o_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/pakuula/go-rusty/option/chan_o"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeq(t *testing.T) {
+	ctx := context.Background()
+	ch := chan_o.FromSeq(ctx, slices.Values([]int{1, 2, 3}))
+	assert.Equal(t, []int{1, 2, 3}, slices.Collect(chan_o.Seq(ch)))
+}
+
+func TestSeqCtx(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := make(chan int)
+	go func() {
+		for i := 0; ; i++ {
+			if chan_o.SendCtx(ctx, ch, i).IsError() {
+				return
+			}
+		}
+	}()
+	var got []int
+	for v := range chan_o.SeqCtx(ctx, ch) {
+		got = append(got, v)
+		if v == 2 {
+			cancel()
+		}
+	}
+	assert.Equal(t, []int{0, 1, 2}, got)
+
+	cancelled, cancel2 := context.WithCancel(context.Background())
+	cancel2()
+	assert.Empty(t, slices.Collect(chan_o.Seq(chan_o.FromSeq(cancelled, slices.Values([]int{1})))))
+}
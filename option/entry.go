@@ -0,0 +1,112 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package option
+
+// A view into a single key of a map, which may or may not be present
+type MapEntry[K comparable, V any] struct {
+	m   map[K]V
+	key K
+}
+
+// Returns the entry of the key in the map.
+// The methods that insert values panic if the map is nil.
+func Entry[K comparable, V any](m map[K]V, key K) MapEntry[K, V] {
+	return MapEntry[K, V]{m: m, key: key}
+}
+
+// Returns the key of the entry
+func (self MapEntry[K, V]) Key() K {
+	return self.key
+}
+
+// Returns the value of the key or None
+func (self MapEntry[K, V]) Get() Option[V] {
+	return MapGet(self.m, self.key)
+}
+
+// Inserts the value if the key is missing and returns the stored value
+func (self MapEntry[K, V]) OrInsert(value V) V {
+	if v, ok := self.m[self.key]; ok {
+		return v
+	}
+	self.m[self.key] = value
+	return value
+}
+
+// Inserts the value of f if the key is missing and returns the stored value
+func (self MapEntry[K, V]) OrInsertWith(f func() V) V {
+	if v, ok := self.m[self.key]; ok {
+		return v
+	}
+	value := f()
+	self.m[self.key] = value
+	return value
+}
+
+// Inserts the zero value if the key is missing and returns the stored value
+func (self MapEntry[K, V]) OrDefault() V {
+	var zero V
+	return self.OrInsert(zero)
+}
+
+// Calls f to modify the value if the key is present
+func (self MapEntry[K, V]) AndModify(f func(*V)) MapEntry[K, V] {
+	if v, ok := self.m[self.key]; ok {
+		f(&v)
+		self.m[self.key] = v
+	}
+	return self
+}
+
+// Stores the value and returns the previous one or None
+func (self MapEntry[K, V]) Insert(value V) Option[V] {
+	old := self.Get()
+	self.m[self.key] = value
+	return old
+}
+
+// Removes the key and returns its value or None
+func (self MapEntry[K, V]) Remove() Option[V] {
+	old := self.Get()
+	if old.IsSome() {
+		delete(self.m, self.key)
+	}
+	return old
+}
+
+// Nested access
+
+// Walks the tree of map[string]any and []any values, e.g. decoded JSON.
+// String steps index maps, int steps index slices.
+// Returns None if any step is missing or does not fit the node.
+func Path(root any, steps ...any) Option[any] {
+	node := root
+	for _, step := range steps {
+		switch key := step.(type) {
+		case string:
+			m, ok := node.(map[string]any)
+			if !ok {
+				return None[any]()
+			}
+			if node, ok = m[key]; !ok {
+				return None[any]()
+			}
+		case int:
+			s, ok := node.([]any)
+			if !ok || key < 0 || key >= len(s) {
+				return None[any]()
+			}
+			node = s[key]
+		default:
+			return None[any]()
+		}
+	}
+	return Some(node)
+}
+
+// Walks the tree like Path and converts the found value to the type T
+func PathAs[T any](root any, steps ...any) Option[T] {
+	return ApplyOption(Path(root, steps...), Cast[T])
+}
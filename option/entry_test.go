@@ -0,0 +1,47 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package option_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pakuula/go-rusty/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntry(t *testing.T) {
+	counts := map[string]int{}
+	for _, w := range []string{"a", "b", "a"} {
+		option.Entry(counts, w).AndModify(func(v *int) { *v++ }).OrInsert(1)
+	}
+	assert.Equal(t, map[string]int{"a": 2, "b": 1}, counts)
+
+	assert.Equal(t, 0, option.Entry(counts, "c").OrDefault())
+	assert.Equal(t, 2, option.Entry(counts, "a").OrInsertWith(func() int { return 10 }))
+	assert.Equal(t, 10, option.Entry(counts, "d").OrInsertWith(func() int { return 10 }))
+
+	assert.Equal(t, 10, option.Entry(counts, "d").Insert(11).Unwrap())
+	assert.True(t, option.Entry(counts, "e").Insert(1).IsNone())
+
+	assert.Equal(t, 11, option.Entry(counts, "d").Remove().Unwrap())
+	assert.True(t, option.Entry(counts, "d").Remove().IsNone())
+	assert.True(t, option.Entry(counts, "d").Get().IsNone())
+}
+
+func TestPath(t *testing.T) {
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal([]byte(`{"a": {"b": [{"c": "x"}, 2]}}`), &doc))
+
+	assert.Equal(t, "x", option.PathAs[string](doc, "a", "b", 0, "c").Unwrap())
+	assert.Equal(t, 2.0, option.PathAs[float64](doc, "a", "b", 1).Unwrap())
+	assert.True(t, option.Path(doc, "a", "b", 2).IsNone())
+	assert.True(t, option.Path(doc, "a", "x").IsNone())
+	assert.True(t, option.Path(doc, "a", 0).IsNone())
+	assert.True(t, option.PathAs[int](doc, "a", "b", 1).IsNone())
+	assert.True(t, option.Path(doc, "a", 1.5).IsNone())
+	assert.True(t, option.Path(doc).IsSome())
+}
@@ -0,0 +1,78 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package option
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+
+	"github.com/pakuula/go-rusty/internal/parse"
+)
+
+// A flag.Value that stores the flag into an option.
+// The option is None until the flag is passed on the command line.
+//
+// If T is a slice, every occurrence of the flag appends an element.
+type Flag[T any] struct {
+	opt *Option[T]
+}
+
+// Builds a flag value that writes into opt and sets opt to None
+func NewFlag[T any](opt *Option[T]) *Flag[T] {
+	*opt = None[T]()
+	return &Flag[T]{opt: opt}
+}
+
+// Defines a flag in the flag set that writes into opt
+func FlagVar[T any](fs *flag.FlagSet, opt *Option[T], name string, usage string) {
+	fs.Var(NewFlag(opt), name, usage)
+}
+
+// A slice is repeated unless parse.Into parses it as a whole, e.g. by UnmarshalText
+func isRepeated(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && !parse.Supported(t)
+}
+
+// Returns the string representation of the value or "" for None
+func (self *Flag[T]) String() string {
+	if self == nil || self.opt == nil || self.opt.IsNone() {
+		return ""
+	}
+	return fmt.Sprint(self.opt.value)
+}
+
+// Parses the value of the flag
+func (self *Flag[T]) Set(s string) error {
+	var value T
+	v := reflect.ValueOf(&value).Elem()
+	if !isRepeated(v.Type()) {
+		if err := parse.Into(s, v); err != nil {
+			return err
+		}
+		*self.opt = Some(value)
+		return nil
+	}
+	elem := reflect.New(v.Type().Elem()).Elem()
+	if err := parse.Into(s, elem); err != nil {
+		return err
+	}
+	if self.opt.IsSome() {
+		v.Set(reflect.ValueOf(self.opt.value))
+	}
+	*self.opt = Some(reflect.Append(v, elem).Interface().(T))
+	return nil
+}
+
+// Returns the stored Option[T]
+func (self *Flag[T]) Get() any {
+	return *self.opt
+}
+
+// True for boolean flags, so that -name is accepted without a value
+func (self *Flag[T]) IsBoolFlag() bool {
+	var zero T
+	return reflect.TypeOf(&zero).Elem().Kind() == reflect.Bool
+}
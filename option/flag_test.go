@@ -0,0 +1,85 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package option_test
+
+import (
+	"flag"
+	"io"
+	"net"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/pakuula/go-rusty/option"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type flags struct {
+	retries option.Option[int]
+	verbose option.Option[bool]
+	timeout option.Option[time.Duration]
+	addr    option.Option[netip.Addr]
+	tags    option.Option[[]string]
+	name    option.Option[string]
+}
+
+func parseFlags(args ...string) (flags, error) {
+	var f flags
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	option.FlagVar(fs, &f.retries, "retries", "number of retries")
+	option.FlagVar(fs, &f.verbose, "v", "verbose")
+	option.FlagVar(fs, &f.timeout, "timeout", "timeout")
+	option.FlagVar(fs, &f.addr, "addr", "address")
+	option.FlagVar(fs, &f.tags, "tag", "tags")
+	option.FlagVar(fs, &f.name, "name", "name")
+	return f, fs.Parse(args)
+}
+
+func TestFlag(t *testing.T) {
+	f, err := parseFlags("-retries=0", "-v", "-timeout", "3s", "-addr", "::1", "-tag", "a", "-tag", "b")
+	require.NoError(t, err)
+	assert.Equal(t, 0, f.retries.Unwrap())
+	assert.True(t, f.verbose.Unwrap())
+	assert.Equal(t, 3*time.Second, f.timeout.Unwrap())
+	assert.Equal(t, netip.MustParseAddr("::1"), f.addr.Unwrap())
+	assert.Equal(t, []string{"a", "b"}, f.tags.Unwrap())
+	assert.True(t, f.name.IsNone())
+
+	f, err = parseFlags()
+	require.NoError(t, err)
+	assert.True(t, f.retries.IsNone())
+	assert.True(t, f.verbose.IsNone())
+	assert.True(t, f.tags.IsNone())
+}
+
+func TestFlagErrors(t *testing.T) {
+	_, err := parseFlags("-retries=many")
+	assert.Error(t, err)
+	_, err = parseFlags("-addr=nowhere")
+	assert.Error(t, err)
+}
+
+func TestFlagGetter(t *testing.T) {
+	var retries option.Option[int]
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	option.FlagVar(fs, &retries, "retries", "number of retries")
+	require.NoError(t, fs.Parse([]string{"-retries", "2"}))
+
+	getter := fs.Lookup("retries").Value.(flag.Getter)
+	assert.Equal(t, retries, getter.Get())
+	assert.Equal(t, "2", getter.String())
+	assert.Equal(t, "", fs.Lookup("retries").DefValue)
+}
+
+func TestFlagTextSlice(t *testing.T) {
+	// net.IP is a slice parsed as a whole by UnmarshalText
+	var ip option.Option[net.IP]
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	option.FlagVar(fs, &ip, "ip", "address")
+	require.NoError(t, fs.Parse([]string{"-ip", "10.0.0.1", "-ip", "10.0.0.2"}))
+	assert.Equal(t, net.ParseIP("10.0.0.2"), ip.Unwrap())
+}
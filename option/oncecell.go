@@ -0,0 +1,52 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package option
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/pakuula/go-rusty/result"
+)
+
+// A cell that can be written only once.
+// The zero value is an empty cell. It is safe to use from multiple goroutines.
+type OnceCell[T any] struct {
+	mu    sync.Mutex
+	value T
+	set   bool
+}
+
+var ErrAlreadySet = errors.New("cell is already set")
+
+// Returns the stored value or None if the cell is empty
+func (self *OnceCell[T]) Get() Option[T] {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return WrapOk(self.value, self.set)
+}
+
+// Returns the stored value. If the cell is empty, stores the value of f and returns it.
+func (self *OnceCell[T]) GetOrInit(f func() T) T {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	if !self.set {
+		self.value = f()
+		self.set = true
+	}
+	return self.value
+}
+
+// Stores the value if the cell is empty, otherwise returns ErrAlreadySet
+func (self *OnceCell[T]) Set(value T) result.ResultVoid {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	if self.set {
+		return result.Void(ErrAlreadySet)
+	}
+	self.value = value
+	self.set = true
+	return result.Void(nil)
+}
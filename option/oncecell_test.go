@@ -0,0 +1,38 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package option_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/pakuula/go-rusty/option"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnceCell(t *testing.T) {
+	var cell option.OnceCell[int]
+	assert.True(t, cell.Get().IsNone())
+	assert.True(t, cell.Set(1).IsValue())
+	assert.ErrorIs(t, cell.Set(2).Err(), option.ErrAlreadySet)
+	assert.Equal(t, 1, cell.Get().Unwrap())
+	assert.Equal(t, 1, cell.GetOrInit(func() int { return 3 }))
+}
+
+func TestOnceCellConcurrent(t *testing.T) {
+	var cell option.OnceCell[int]
+	var calls int
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cell.GetOrInit(func() int { calls++; return 42 })
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 42, cell.Get().Unwrap())
+}
@@ -10,6 +10,8 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+
+	"github.com/pakuula/go-rusty/tuple"
 )
 
 // A value or None
@@ -192,11 +194,35 @@ func (self Option[T]) UnwrapOrDefault() T {
 	return self.value
 }
 
+// Returns the stored value or the value of f
+func (self Option[T]) UnwrapOrElse(f func() T) T {
+	if self.IsNone() {
+		return f()
+	}
+	return self.value
+}
+
 // Converts to the pair (value, bool)
 func (self Option[T]) UnwrapWithOk() (T, bool) {
 	return self.value, !self.none
 }
 
+// Replacing the stored value
+
+// Returns the stored option and leaves None in its place
+func (self *Option[T]) Take() Option[T] {
+	old := *self
+	*self = None[T]()
+	return old
+}
+
+// Stores the value and returns the old option
+func (self *Option[T]) Replace(value T) Option[T] {
+	old := *self
+	*self = Some(value)
+	return old
+}
+
 // Accessing the error
 
 // Pointer and dereference
@@ -249,6 +275,31 @@ func ApplyOption[T any, U any](from Option[T], f func(T) Option[U]) Option[U] {
 	return f(from.value)
 }
 
+// Calls onSome or onNone depending on the content of the option
+func Match[T any, U any](opt Option[T], onSome func(T) U, onNone func() U) U {
+	if opt.IsNone() {
+		return onNone()
+	}
+	return onSome(opt.value)
+}
+
+// Combines two options into an option of a pair.
+// Returns None if either of the options is None.
+func Zip[A any, B any](a Option[A], b Option[B]) Option[tuple.Pair[A, B]] {
+	if a.IsNone() || b.IsNone() {
+		return None[tuple.Pair[A, B]]()
+	}
+	return Some(tuple.NewPair(a.value, b.value))
+}
+
+// Splits an option of a pair into a pair of options
+func Unzip[A any, B any](opt Option[tuple.Pair[A, B]]) (Option[A], Option[B]) {
+	if opt.IsNone() {
+		return None[A](), None[B]()
+	}
+	return Some(opt.value.First), Some(opt.value.Second)
+}
+
 // Utility functions
 
 // Unmarshal JSON data into a value of the type T or error
@@ -263,6 +314,32 @@ func UnmarshalJsonString[T any](data string) Option[T] {
 	return UnmarshalJson[T]([]byte(data))
 }
 
+// Encodes None as null and Some(value) as the value
+func (self Option[T]) MarshalJSON() ([]byte, error) {
+	if self.IsNone() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(self.value)
+}
+
+// Decodes null as None and any other JSON value as Some(value).
+//
+// encoding/json does not call UnmarshalJSON for a key missing from the document,
+// the field keeps its value. The zero Option is Some(zero value),
+// so a field that must become None when the key is missing should be initialised with None.
+func (self *Option[T]) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*self = None[T]()
+		return nil
+	}
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*self = Some(value)
+	return nil
+}
+
 // Map access
 
 func MapGet[K comparable, V any](m map[K]V, key K) Option[V] {
@@ -5,6 +5,7 @@
 package option_test
 
 import (
+	"encoding/json"
 	"errors"
 	"testing"
 
@@ -38,3 +39,60 @@ func TestCTOR(t *testing.T) {
 	}
 
 }
+
+func TestZip(t *testing.T) {
+	zipped := option.Zip(SomeTR(1), option.Some("one"))
+	require.True(t, zipped.IsSome())
+	assert.Equal(t, "(1, one)", zipped.String())
+	assert.True(t, option.Zip(NoneTR(), option.Some("one")).IsNone())
+
+	a, b := option.Unzip(zipped)
+	assert.Equal(t, 1, a.Unwrap())
+	assert.Equal(t, "one", b.Unwrap())
+}
+
+func TestMatch(t *testing.T) {
+	describe := func(opt TR) string {
+		return option.Match(opt,
+			func(v int) string { return "some" },
+			func() string { return "none" },
+		)
+	}
+	assert.Equal(t, "some", describe(SomeTR(1)))
+	assert.Equal(t, "none", describe(NoneTR()))
+}
+
+func TestTake(t *testing.T) {
+	opt := SomeTR(1)
+	assert.Equal(t, 1, opt.Take().Unwrap())
+	assert.True(t, opt.IsNone())
+	assert.True(t, opt.Replace(2).IsNone())
+	assert.Equal(t, 2, opt.Unwrap())
+}
+
+func TestJSON(t *testing.T) {
+	type doc struct {
+		A option.Option[int] `json:"a"`
+		B option.Option[int] `json:"b"`
+	}
+	bz, err := json.Marshal(doc{A: SomeTR(1), B: NoneTR()})
+	require.NoError(t, err)
+	assert.Equal(t, `{"a":1,"b":null}`, string(bz))
+
+	var decoded doc
+	require.NoError(t, json.Unmarshal(bz, &decoded))
+	assert.Equal(t, 1, decoded.A.Unwrap())
+	assert.True(t, decoded.B.IsNone())
+
+	// A missing key leaves the field as it was
+	var zero doc
+	require.NoError(t, json.Unmarshal([]byte(`{}`), &zero))
+	assert.Equal(t, 0, zero.A.Unwrap())
+	missing := doc{A: NoneTR(), B: NoneTR()}
+	require.NoError(t, json.Unmarshal([]byte(`{"b": 2}`), &missing))
+	assert.True(t, missing.A.IsNone())
+	assert.Equal(t, 2, missing.B.Unwrap())
+
+	var invalid doc
+	assert.Error(t, json.Unmarshal([]byte(`{"a": "x"}`), &invalid))
+}
@@ -0,0 +1,154 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package regexp_o
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+
+	"github.com/pakuula/go-rusty/internal/parse"
+	"github.com/pakuula/go-rusty/option"
+	"github.com/pakuula/go-rusty/result"
+	"github.com/pakuula/go-rusty/tuple"
+)
+
+// Returns the leftmost match or None. Unlike regexp.FindString,
+// an empty match is Some("").
+func FindString(re *regexp.Regexp, s string) option.Option[string] {
+	loc := re.FindStringIndex(s)
+	if loc == nil {
+		return option.None[string]()
+	}
+	return option.Some(s[loc[0]:loc[1]])
+}
+
+// Returns the byte offsets (start, end) of the leftmost match or None
+func FindStringIndex(re *regexp.Regexp, s string) option.Option[tuple.Pair[int, int]] {
+	loc := re.FindStringIndex(s)
+	if loc == nil {
+		return option.None[tuple.Pair[int, int]]()
+	}
+	return option.Some(tuple.NewPair(loc[0], loc[1]))
+}
+
+// The groups captured by a match
+type Captures struct {
+	re   *regexp.Regexp
+	s    string
+	locs []int
+}
+
+func newCaptures(re *regexp.Regexp, s string, locs []int) Captures {
+	return Captures{re: re, s: s, locs: locs}
+}
+
+// Returns the number of groups including the whole match
+func (self Captures) Len() int {
+	return len(self.locs) / 2
+}
+
+// Returns the whole match
+func (self Captures) Match() string {
+	return self.s[self.locs[0]:self.locs[1]]
+}
+
+// Returns the i-th group or None if the group does not exist or did not participate in the match
+func (self Captures) Get(i int) option.Option[string] {
+	if i < 0 || i >= self.Len() || self.locs[2*i] < 0 {
+		return option.None[string]()
+	}
+	return option.Some(self.s[self.locs[2*i]:self.locs[2*i+1]])
+}
+
+// Returns the named group or None if the group does not exist or did not participate in the match
+func (self Captures) Name(name string) option.Option[string] {
+	i := self.re.SubexpIndex(name)
+	if i < 0 {
+		return option.None[string]()
+	}
+	return self.Get(i)
+}
+
+// Returns the leftmost match with its groups or None
+func FindSubmatch(re *regexp.Regexp, s string) option.Option[Captures] {
+	locs := re.FindStringSubmatchIndex(s)
+	if locs == nil {
+		return option.None[Captures]()
+	}
+	return option.Some(newCaptures(re, s, locs))
+}
+
+// Returns at most n successive matches with their groups; all of them if n < 0
+func FindAllSubmatch(re *regexp.Regexp, s string, n int) []Captures {
+	all := re.FindAllStringSubmatchIndex(s, n)
+	retval := make([]Captures, len(all))
+	for i, locs := range all {
+		retval[i] = newCaptures(re, s, locs)
+	}
+	return retval
+}
+
+// Binding groups to struct fields
+
+var ErrNoMatch = errors.New("regexp does not match")
+var ErrNotStruct = errors.New("bind target is not a struct")
+
+// The value of a group can't be stored in the field
+type BindError struct {
+	Group string
+	Value string
+	Err   error
+}
+
+func (self *BindError) Error() string {
+	return fmt.Sprintf("group %q: cannot parse %q: %s", self.Group, self.Value, self.Err)
+}
+
+func (self *BindError) Unwrap() error {
+	return self.Err
+}
+
+// Matches s and fills the fields of the struct T from the named groups.
+//
+// A field is bound to the group named by its `regexp:"name"` tag or, without a tag,
+// to the group with the same name as the field. Fields tagged `regexp:"-"` are skipped.
+// Groups that did not participate in the match leave the fields zero.
+// Values are parsed the same way as strings_o.ParseAs does; types implementing
+// encoding.TextUnmarshaler are supported too.
+func Bind[T any](re *regexp.Regexp, s string) result.Result[T] {
+	var value T
+	v := reflect.ValueOf(&value).Elem()
+	if v.Kind() != reflect.Struct {
+		return result.Err[T](ErrNotStruct)
+	}
+	caps := FindSubmatch(re, s)
+	if caps.IsNone() {
+		return result.Err[T](ErrNoMatch)
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		group := field.Name
+		if tag, ok := field.Tag.Lookup("regexp"); ok {
+			group = tag
+		}
+		if group == "-" {
+			continue
+		}
+		text := caps.Unwrap().Name(group)
+		if text.IsNone() {
+			continue
+		}
+		if err := parse.Into(text.Unwrap(), v.Field(i)); err != nil {
+			return result.Err[T](&BindError{Group: group, Value: text.Unwrap(), Err: err})
+		}
+	}
+	return result.Val(value)
+}
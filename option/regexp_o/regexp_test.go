@@ -0,0 +1,70 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package regexp_o_test
+
+import (
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pakuula/go-rusty/option/regexp_o"
+	"github.com/pakuula/go-rusty/tuple"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFind(t *testing.T) {
+	re := regexp.MustCompile(`a*`)
+	assert.Equal(t, "", regexp_o.FindString(re, "bbb").Unwrap())
+	assert.Equal(t, "aa", regexp_o.FindString(re, "aab").Unwrap())
+	assert.True(t, regexp_o.FindString(regexp.MustCompile(`x`), "abc").IsNone())
+	assert.Equal(t, tuple.NewPair(1, 2), regexp_o.FindStringIndex(regexp.MustCompile(`b`), "abc").Unwrap())
+}
+
+func TestCaptures(t *testing.T) {
+	re := regexp.MustCompile(`(?P<key>\w+)=(?P<value>\w+)?`)
+	caps := regexp_o.FindSubmatch(re, "name=bob").Unwrap()
+	assert.Equal(t, 3, caps.Len())
+	assert.Equal(t, "name=bob", caps.Match())
+	assert.Equal(t, "name", caps.Name("key").Unwrap())
+	assert.Equal(t, "bob", caps.Get(2).Unwrap())
+	assert.True(t, caps.Name("missing").IsNone())
+	assert.True(t, caps.Get(3).IsNone())
+
+	empty := regexp_o.FindSubmatch(re, "name=").Unwrap()
+	assert.True(t, empty.Name("value").IsNone())
+
+	assert.True(t, regexp_o.FindSubmatch(re, "===").IsNone())
+	assert.Len(t, regexp_o.FindAllSubmatch(re, "a=1 b=2 c=3", -1), 3)
+}
+
+type Entry struct {
+	Year    int           `regexp:"year"`
+	Month   uint8         `regexp:"month"`
+	Timeout time.Duration `regexp:"timeout"`
+	Name    string
+	Skipped string `regexp:"-"`
+}
+
+var reEntry = regexp.MustCompile(`(?P<year>\d+)-(?P<month>\d+) (?P<Name>\w+)(?: (?P<timeout>\S+))?`)
+
+func TestBind(t *testing.T) {
+	entry := regexp_o.Bind[Entry](reEntry, "2024-05 backup 30s").Unwrap()
+	assert.Equal(t, Entry{Year: 2024, Month: 5, Timeout: 30 * time.Second, Name: "backup"}, entry)
+
+	entry = regexp_o.Bind[Entry](reEntry, "2024-05 backup").Unwrap()
+	assert.Equal(t, time.Duration(0), entry.Timeout)
+
+	assert.ErrorIs(t, regexp_o.Bind[Entry](reEntry, "nothing").Err(), regexp_o.ErrNoMatch)
+	assert.ErrorIs(t, regexp_o.Bind[int](reEntry, "2024-05 backup").Err(), regexp_o.ErrNotStruct)
+
+	res := regexp_o.Bind[Entry](reEntry, "2024-300 backup")
+	var bindErr *regexp_o.BindError
+	require.ErrorAs(t, res.Err(), &bindErr)
+	assert.Equal(t, "month", bindErr.Group)
+	assert.Equal(t, "300", bindErr.Value)
+	assert.ErrorIs(t, res.Err(), strconv.ErrRange)
+}
@@ -0,0 +1,92 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package strings_o
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pakuula/go-rusty/internal/parse"
+	"github.com/pakuula/go-rusty/option"
+	"github.com/pakuula/go-rusty/result"
+	"github.com/pakuula/go-rusty/tuple"
+)
+
+// Splits s around the first instance of sep.
+// Returns None if sep is not found.
+func SplitOnce(s, sep string) option.Option[tuple.Pair[string, string]] {
+	before, after, found := strings.Cut(s, sep)
+	return option.WrapOk(tuple.NewPair(before, after), found)
+}
+
+// Splits s around the last instance of sep.
+// Returns None if sep is not found.
+func RSplitOnce(s, sep string) option.Option[tuple.Pair[string, string]] {
+	i := strings.LastIndex(s, sep)
+	if i < 0 {
+		return option.None[tuple.Pair[string, string]]()
+	}
+	return option.Some(tuple.NewPair(s[:i], s[i+len(sep):]))
+}
+
+// Returns s without the prefix or None if s does not start with it
+func StripPrefix(s, prefix string) option.Option[string] {
+	return option.WrapOk(strings.CutPrefix(s, prefix))
+}
+
+// Returns s without the suffix or None if s does not end with it
+func StripSuffix(s, suffix string) option.Option[string] {
+	return option.WrapOk(strings.CutSuffix(s, suffix))
+}
+
+// Returns the byte index of the first instance of sub or None
+func Find(s, sub string) option.Option[int] {
+	i := strings.Index(s, sub)
+	return option.WrapOk(i, i >= 0)
+}
+
+// Returns the byte index of the last instance of sub or None
+func RFind(s, sub string) option.Option[int] {
+	i := strings.LastIndex(s, sub)
+	return option.WrapOk(i, i >= 0)
+}
+
+// Returns the i-th rune of s or None if s is shorter.
+// Note that i counts runes, not bytes.
+func CharAt(s string, i int) option.Option[rune] {
+	if i < 0 {
+		return option.None[rune]()
+	}
+	for _, r := range s {
+		if i == 0 {
+			return option.Some(r)
+		}
+		i--
+	}
+	return option.None[rune]()
+}
+
+// Returns the rune starting at the byte offset i or None if i is out of range
+// or does not start a valid UTF-8 sequence.
+func RuneAt(s string, i int) option.Option[rune] {
+	if i < 0 || i >= len(s) {
+		return option.None[rune]()
+	}
+	r, size := utf8.DecodeRuneInString(s[i:])
+	return option.WrapOk(r, r != utf8.RuneError || size > 1)
+}
+
+// Types supported by ParseAs. time.Duration is parsed by time.ParseDuration.
+type Parsable interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64 | ~bool | ~string
+}
+
+// Parses s into a number, a boolean or a time.Duration.
+// Integers are decimal unless they have one of the base prefixes 0x, 0o and 0b.
+func ParseAs[T Parsable](s string) result.Result[T] {
+	return result.Wrap(parse.As[T](s))
+}
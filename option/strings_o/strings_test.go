@@ -0,0 +1,54 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package strings_o_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pakuula/go-rusty/option/strings_o"
+	"github.com/pakuula/go-rusty/tuple"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplit(t *testing.T) {
+	assert.Equal(t, tuple.NewPair("key", "a=b"), strings_o.SplitOnce("key=a=b", "=").Unwrap())
+	assert.Equal(t, tuple.NewPair("key=a", "b"), strings_o.RSplitOnce("key=a=b", "=").Unwrap())
+	assert.True(t, strings_o.SplitOnce("key", "=").IsNone())
+	assert.True(t, strings_o.RSplitOnce("key", "=").IsNone())
+}
+
+func TestStrip(t *testing.T) {
+	assert.Equal(t, "bar", strings_o.StripPrefix("foobar", "foo").Unwrap())
+	assert.True(t, strings_o.StripPrefix("foobar", "bar").IsNone())
+	assert.Equal(t, "foo", strings_o.StripSuffix("foobar", "bar").Unwrap())
+	assert.True(t, strings_o.StripSuffix("foobar", "foo").IsNone())
+}
+
+func TestFind(t *testing.T) {
+	assert.Equal(t, 1, strings_o.Find("abcabc", "bc").Unwrap())
+	assert.Equal(t, 4, strings_o.RFind("abcabc", "bc").Unwrap())
+	assert.True(t, strings_o.Find("abc", "x").IsNone())
+}
+
+func TestCharAt(t *testing.T) {
+	assert.Equal(t, 'ж', strings_o.CharAt("ёж!", 1).Unwrap())
+	assert.True(t, strings_o.CharAt("ёж!", 3).IsNone())
+	assert.True(t, strings_o.CharAt("ёж!", -1).IsNone())
+	assert.Equal(t, 'ж', strings_o.RuneAt("ёж!", 2).Unwrap())
+	assert.True(t, strings_o.RuneAt("ёж!", 1).IsNone())
+	assert.True(t, strings_o.RuneAt("ёж!", 5).IsNone())
+}
+
+func TestParseAs(t *testing.T) {
+	assert.Equal(t, 42, strings_o.ParseAs[int]("42").Unwrap())
+	assert.Equal(t, uint8(255), strings_o.ParseAs[uint8]("0xff").Unwrap())
+	assert.Equal(t, 1.5, strings_o.ParseAs[float64]("1.5").Unwrap())
+	assert.Equal(t, true, strings_o.ParseAs[bool]("true").Unwrap())
+	assert.Equal(t, 2*time.Second, strings_o.ParseAs[time.Duration]("2s").Unwrap())
+	assert.ErrorIs(t, strings_o.ParseAs[int8]("300").Err(), strconv.ErrRange)
+	assert.ErrorIs(t, strings_o.ParseAs[int]("x").Err(), strconv.ErrSyntax)
+}
@@ -4,6 +4,8 @@
 
 package io_r
 
+//go:generate go run -C ../../cmd ./rusty-wrap -pkg io -name $GOPACKAGE -allow Copy,CopyBuffer,CopyN,ReadAll,ReadAtLeast,ReadFull,WriteString -o ../result/io_r/io_gen.go
+
 import (
 	"io"
 
@@ -57,31 +59,3 @@ func Write(w io.Writer, p []byte) result.Result[int] {
 func WriteAt(w io.WriterAt, p []byte, off int64) result.Result[int] {
 	return result.Wrap(w.WriteAt(p, off))
 }
-
-func Copy(dst io.Writer, src io.Reader) result.Result[int64] {
-	return result.Wrap(io.Copy(dst, src))
-}
-
-func CopyBuffer(dst io.Writer, src io.Reader, buf []byte) result.Result[int64] {
-	return result.Wrap(io.CopyBuffer(dst, src, buf))
-}
-
-func CopyN(dst io.Writer, src io.Reader, n int64) result.Result[int64] {
-	return result.Wrap(io.CopyN(dst, src, n))
-}
-
-func ReadAll(r io.Reader) result.Result[[]byte] {
-	return result.Wrap(io.ReadAll(r))
-}
-
-func ReadAtLeast(r io.Reader, buf []byte, min int) result.Result[int] {
-	return result.Wrap(io.ReadAtLeast(r, buf, min))
-}
-
-func ReadFull(r io.Reader, buf []byte) result.Result[int] {
-	return result.Wrap(io.ReadFull(r, buf))
-}
-
-func WriteString(w io.Writer, s string) result.Result[int] {
-	return result.Wrap(io.WriteString(w, s))
-}
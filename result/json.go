@@ -0,0 +1,191 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package result
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// The error of a decoded Result whose type is not registered by RegisterError
+type JsonError struct {
+	// The name of the type, empty if the encoded error was not registered
+	Type    string
+	Message string
+}
+
+func (self *JsonError) Error() string {
+	return self.Message
+}
+
+// The JSON error registry: the names of the error types and back
+var registry = struct {
+	sync.RWMutex
+	types map[string]reflect.Type
+	names map[reflect.Type]string
+}{
+	types: map[string]reflect.Type{},
+	names: map[reflect.Type]string{},
+}
+
+// Registers the error type E for the JSON encoding of Result.
+// The error of the type E is encoded with the name and decoded back into E,
+// the other errors are decoded as *JsonError.
+//
+// Panics if the name or the type is already registered.
+func RegisterError[E error](name string) {
+	t := reflect.TypeOf((*E)(nil)).Elem()
+	if t.Kind() == reflect.Interface {
+		panic(fmt.Sprintf("RegisterError: %s is an interface", t))
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	if other, ok := registry.types[name]; ok {
+		panic(fmt.Sprintf("RegisterError: %q is already registered for %s", name, other))
+	}
+	if other, ok := registry.names[t]; ok {
+		panic(fmt.Sprintf("RegisterError: %s is already registered as %q", t, other))
+	}
+	registry.types[name] = t
+	registry.names[t] = name
+}
+
+// The encoded error
+type jsonError struct {
+	Type    string          `json:"type,omitempty"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// The encoded Result: {"value": value} or {"error": {"type": name, "message": text, "data": error}}
+type jsonResult struct {
+	Value json.RawMessage `json:"value,omitempty"`
+	Error *jsonError      `json:"error,omitempty"`
+}
+
+// Encodes the value as {"value": value} and the error as {"error": {"message": text}}.
+// The error of a registered type also has the name of the type and the error itself:
+// {"error": {"type": name, "message": text, "data": error}}.
+func (self Result[T]) MarshalJSON() ([]byte, error) {
+	if self.IsValue() {
+		value, err := json.Marshal(self.value)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(jsonResult{Value: value})
+	}
+	encoded := &jsonError{Message: self.err.Error()}
+	registry.RLock()
+	name, ok := registry.names[reflect.TypeOf(self.err)]
+	registry.RUnlock()
+	if ok {
+		data, err := json.Marshal(self.err)
+		if err != nil {
+			return nil, err
+		}
+		encoded.Type, encoded.Data = name, data
+	}
+	return json.Marshal(jsonResult{Error: encoded})
+}
+
+var ErrJsonResult = errors.New("expected {\"value\": value} or {\"error\": error}")
+
+// Decodes the Result encoded by MarshalJSON.
+//
+// The fields of the interface types such as error, e.g. the sources of the errors,
+// can't be decoded by encoding/json. They are skipped and are nil after decoding.
+func (self *Result[T]) UnmarshalJSON(data []byte) error {
+	var decoded jsonResult
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	switch {
+	case decoded.Value != nil && decoded.Error == nil:
+		var value T
+		if err := json.Unmarshal(decoded.Value, &value); err != nil {
+			return err
+		}
+		*self = Val(value)
+	case decoded.Error != nil && decoded.Value == nil:
+		err, decodeErr := decodeError(decoded.Error)
+		if decodeErr != nil {
+			return decodeErr
+		}
+		*self = Err[T](err)
+	default:
+		return ErrJsonResult
+	}
+	return nil
+}
+
+func decodeError(encoded *jsonError) (error, error) {
+	registry.RLock()
+	t, ok := registry.types[encoded.Type]
+	registry.RUnlock()
+	if !ok || encoded.Data == nil {
+		return &JsonError{Type: encoded.Type, Message: encoded.Message}, nil
+	}
+	data := encoded.Data
+	if keys := interfaceKeys(t); len(keys) != 0 {
+		var err error
+		if data, err = dropKeys(data, keys); err != nil {
+			return nil, fmt.Errorf("error %s: %w", encoded.Type, err)
+		}
+	}
+	ptr := reflect.New(t)
+	if err := json.Unmarshal(data, ptr.Interface()); err != nil {
+		return nil, fmt.Errorf("error %s: %w", encoded.Type, err)
+	}
+	return ptr.Elem().Interface().(error), nil
+}
+
+// Returns the JSON keys of the struct fields of non-empty interface types, such as error,
+// that encoding/json can't decode. The fields of embedded structs are included.
+func interfaceKeys(t reflect.Type) []string {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		name, _, _ := strings.Cut(tag, ",")
+		switch {
+		case tag == "-":
+		case sf.Anonymous && name == "":
+			keys = append(keys, interfaceKeys(sf.Type)...)
+		case !sf.IsExported():
+		case sf.Type.Kind() == reflect.Interface && sf.Type.NumMethod() != 0:
+			if name == "" {
+				name = sf.Name
+			}
+			keys = append(keys, name)
+		}
+	}
+	return keys
+}
+
+// Removes the keys from the JSON object, matching them without case like encoding/json
+func dropKeys(data json.RawMessage, keys []string) (json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	for field := range fields {
+		for _, key := range keys {
+			if strings.EqualFold(field, key) {
+				delete(fields, field)
+			}
+		}
+	}
+	return json.Marshal(fields)
+}
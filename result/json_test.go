@@ -0,0 +1,113 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package result_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"testing"
+
+	"github.com/pakuula/go-rusty/result"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type quotaError struct {
+	User  string
+	Limit int
+}
+
+func (self quotaError) Error() string {
+	return fmt.Sprintf("user %s exceeded %d", self.User, self.Limit)
+}
+
+type wrappedError struct {
+	Op     string
+	Source error
+}
+
+func (self *wrappedError) Error() string {
+	if self.Source == nil {
+		return self.Op
+	}
+	return self.Op + ": " + self.Source.Error()
+}
+
+func init() {
+	result.RegisterError[quotaError]("result_test.quotaError")
+	result.RegisterError[*wrappedError]("result_test.wrappedError")
+}
+
+func TestJsonValue(t *testing.T) {
+	bz, err := json.Marshal(ValTR(1))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"value": 1}`, string(bz))
+
+	var decoded TR
+	require.NoError(t, json.Unmarshal(bz, &decoded))
+	assert.Equal(t, 1, decoded.Unwrap())
+
+	bz, err = json.Marshal(result.Void(nil))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"value": {}}`, string(bz))
+
+	var ptr result.Result[*int]
+	require.NoError(t, json.Unmarshal([]byte(`{"value": null}`), &ptr))
+	assert.Nil(t, ptr.Unwrap())
+}
+
+func TestJsonError(t *testing.T) {
+	bz, err := json.Marshal(ErrTR(quotaError{User: "bob", Limit: 3}))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"error": {
+		"type": "result_test.quotaError",
+		"message": "user bob exceeded 3",
+		"data": {"User": "bob", "Limit": 3}
+	}}`, string(bz))
+
+	var decoded TR
+	require.NoError(t, json.Unmarshal(bz, &decoded))
+	var quota quotaError
+	require.True(t, errors.As(decoded.Err(), &quota))
+	assert.Equal(t, quotaError{User: "bob", Limit: 3}, quota)
+
+	// The source is lost
+	bz, err = json.Marshal(ErrTR(&wrappedError{Op: "open", Source: &fs.PathError{Op: "open", Path: "x", Err: fs.ErrNotExist}}))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(bz, &decoded))
+	var wrapped *wrappedError
+	require.True(t, errors.As(decoded.Err(), &wrapped))
+	assert.Equal(t, &wrappedError{Op: "open"}, wrapped)
+
+	// Not registered
+	bz, err = json.Marshal(ErrTR(errTest))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"error": {"message": "test error"}}`, string(bz))
+	require.NoError(t, json.Unmarshal(bz, &decoded))
+	assert.Equal(t, &result.JsonError{Message: "test error"}, decoded.Err())
+
+	require.NoError(t, json.Unmarshal([]byte(`{"error": {"type": "other.Error", "message": "m", "data": {}}}`), &decoded))
+	assert.Equal(t, &result.JsonError{Type: "other.Error", Message: "m"}, decoded.Err())
+}
+
+func TestJsonInvalid(t *testing.T) {
+	decoded := ValTR(1)
+	for _, data := range []string{`{}`, `{"value": 1, "error": {"message": "m"}}`, `[]`, `{"value": "x"}`,
+		`{"error": {"type": "result_test.quotaError", "message": "m", "data": {"Limit": "x"}}}`,
+		`{"error": {"type": "result_test.wrappedError", "message": "m", "data": {"Source": {}, "Op": 5}}}`,
+		`{"error": {"type": "result_test.wrappedError", "message": "m", "data": "open"}}`} {
+		assert.Error(t, json.Unmarshal([]byte(data), &decoded), data)
+	}
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{}`), &decoded), result.ErrJsonResult)
+	assert.Equal(t, 1, decoded.Unwrap())
+}
+
+func TestRegisterError(t *testing.T) {
+	assert.Panics(t, func() { result.RegisterError[quotaError]("other") })
+	assert.Panics(t, func() { result.RegisterError[*fs.PathError]("result_test.quotaError") })
+	assert.Panics(t, func() { result.RegisterError[error]("error") })
+}
@@ -0,0 +1,60 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package result
+
+import "sync"
+
+// Defines what Lazy does when the computation fails
+type ErrorPolicy int
+
+const (
+	// The error is stored and returned by every subsequent Get
+	CacheErrors ErrorPolicy = iota
+	// The error is returned but not stored: the next Get computes again
+	RetryErrors
+)
+
+// A value computed on the first access.
+// It is safe to call Get from multiple goroutines.
+type Lazy[T any] struct {
+	mu     sync.Mutex
+	f      func() Result[T]
+	policy ErrorPolicy
+	done   bool
+	res    Result[T]
+}
+
+// Builds a Lazy object that calls f on the first Get
+func NewLazy[T any](f func() Result[T], policy ErrorPolicy) *Lazy[T] {
+	return &Lazy[T]{f: f, policy: policy}
+}
+
+// Builds a Lazy object from a function returning the pair (value, error)
+func NewLazyE[T any](f func() (T, error), policy ErrorPolicy) *Lazy[T] {
+	return NewLazy(func() Result[T] { return Wrap(f()) }, policy)
+}
+
+// Returns the computed result, computing it if needed
+func (self *Lazy[T]) Get() Result[T] {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	if self.done {
+		return self.res
+	}
+	res := self.f()
+	if res.IsValue() || self.policy == CacheErrors {
+		self.res = res
+		self.done = true
+		self.f = nil
+	}
+	return res
+}
+
+// True if the result is computed and stored
+func (self *Lazy[T]) IsDone() bool {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.done
+}
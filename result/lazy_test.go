@@ -0,0 +1,57 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package result_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/pakuula/go-rusty/result"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLazy(t *testing.T) {
+	calls := 0
+	lazy := result.NewLazy(func() TR { calls++; return ValTR(calls) }, result.CacheErrors)
+	assert.False(t, lazy.IsDone())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, 1, lazy.Get().Unwrap())
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, calls)
+	assert.True(t, lazy.IsDone())
+}
+
+func TestLazyErrorPolicy(t *testing.T) {
+	failing := func(calls *int) func() TR {
+		return func() TR {
+			*calls++
+			if *calls == 1 {
+				return ErrTR(errTest)
+			}
+			return ValTR(*calls)
+		}
+	}
+
+	cached := 0
+	lazy := result.NewLazy(failing(&cached), result.CacheErrors)
+	assert.ErrorIs(t, lazy.Get().Err(), errTest)
+	assert.ErrorIs(t, lazy.Get().Err(), errTest)
+	assert.Equal(t, 1, cached)
+
+	retried := 0
+	lazy = result.NewLazy(failing(&retried), result.RetryErrors)
+	assert.ErrorIs(t, lazy.Get().Err(), errTest)
+	assert.False(t, lazy.IsDone())
+	assert.Equal(t, 2, lazy.Get().Unwrap())
+	assert.Equal(t, 2, lazy.Get().Unwrap())
+	assert.Equal(t, 2, retried)
+}
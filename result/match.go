@@ -0,0 +1,79 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package result
+
+import "errors"
+
+// Calls onValue or onError depending on the content of the result
+func Match[T any, U any](res Result[T], onValue func(T) U, onError func(error) U) U {
+	if res.IsError() {
+		return onError(res.err)
+	}
+	return onValue(res.value)
+}
+
+// A handler of the error case: returns the value and true if it accepts the error
+type ErrCase[U any] func(error) (U, bool)
+
+// Builds a case that accepts errors matching E by errors.As
+func CaseAs[E error, U any](h func(E) U) ErrCase[U] {
+	return func(err error) (U, bool) {
+		var target E
+		if errors.As(err, &target) {
+			return h(target), true
+		}
+		var zero U
+		return zero, false
+	}
+}
+
+// Dispatches the error of a result to the first matching handler.
+// Build it with MatchErr, add the cases and finish with Default.
+type ErrMatcher[U any] struct {
+	err   error
+	done  bool
+	value U
+}
+
+// Starts matching the result.
+// If res is a value, onValue is called and all the error cases are skipped.
+func MatchErr[T any, U any](res Result[T], onValue func(T) U) ErrMatcher[U] {
+	if res.IsValue() {
+		return ErrMatcher[U]{done: true, value: onValue(res.value)}
+	}
+	return ErrMatcher[U]{err: res.err}
+}
+
+// Calls h if the error matches target by errors.Is
+func (self ErrMatcher[U]) Is(target error, h func(error) U) ErrMatcher[U] {
+	return self.When(func(err error) bool { return errors.Is(err, target) }, h)
+}
+
+// Calls the case if it accepts the error
+func (self ErrMatcher[U]) As(c ErrCase[U]) ErrMatcher[U] {
+	if self.done {
+		return self
+	}
+	if value, ok := c(self.err); ok {
+		return ErrMatcher[U]{done: true, value: value}
+	}
+	return self
+}
+
+// Calls h if the error matches the condition
+func (self ErrMatcher[U]) When(cond func(error) bool, h func(error) U) ErrMatcher[U] {
+	if self.done || !cond(self.err) {
+		return self
+	}
+	return ErrMatcher[U]{done: true, value: h(self.err)}
+}
+
+// Returns the value of the matched case or calls h for the unmatched error
+func (self ErrMatcher[U]) Default(h func(error) U) U {
+	if self.done {
+		return self.value
+	}
+	return h(self.err)
+}
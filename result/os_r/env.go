@@ -0,0 +1,110 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package os_r
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pakuula/go-rusty/internal/parse"
+	"github.com/pakuula/go-rusty/option"
+	"github.com/pakuula/go-rusty/result"
+)
+
+var ErrEnvNotSet = errors.New("environment variable is not set")
+
+// An error related to an environment variable
+type EnvError struct {
+	Key   string
+	Value string
+	Err   error
+}
+
+func (self *EnvError) Error() string {
+	if errors.Is(self.Err, ErrEnvNotSet) {
+		return fmt.Sprintf("%s: %s", self.Key, self.Err)
+	}
+	return fmt.Sprintf("%s=%q: %s", self.Key, self.Value, self.Err)
+}
+
+func (self *EnvError) Unwrap() error {
+	return self.Err
+}
+
+// Returns the value of the variable or *EnvError wrapping ErrEnvNotSet
+func RequireEnv(key string) result.Result[string] {
+	value, ok := os.LookupEnv(key)
+	if !ok {
+		return result.Err[string](&EnvError{Key: key, Err: ErrEnvNotSet})
+	}
+	return result.Val(value)
+}
+
+// Looks up the variable and parses its value.
+// Returns None if the variable is not set, and *EnvError if the value can't be parsed.
+//
+// Supported are integers, floats, booleans, strings, time.Duration, url.URL
+// and the types implementing encoding.TextUnmarshaler.
+func LookupEnvAs[T any](key string) option.Option[result.Result[T]] {
+	return option.Apply(LookupEnv(key), func(value string) result.Result[T] {
+		parsed, err := parse.As[T](value)
+		if err != nil {
+			return result.Err[T](&EnvError{Key: key, Value: value, Err: err})
+		}
+		return result.Val(parsed)
+	})
+}
+
+// Looks up the variable and parses its value.
+// Returns *EnvError if the variable is not set or the value can't be parsed.
+func RequireEnvAs[T any](key string) result.Result[T] {
+	return LookupEnvAs[T](key).UnwrapOrElse(func() result.Result[T] {
+		return result.Err[T](&EnvError{Key: key, Err: ErrEnvNotSet})
+	})
+}
+
+// Environment snapshots
+
+// A copy of the environment
+type Snapshot struct {
+	vars []string
+}
+
+// Takes a copy of the environment
+func EnvSnapshot() Snapshot {
+	return Snapshot{vars: os.Environ()}
+}
+
+// Replaces the environment with the snapshot
+func (self Snapshot) Restore() (res result.ResultVoid) {
+	defer result.Catch(&res)
+	os.Clearenv()
+	for _, kv := range self.vars {
+		key, value, _ := strings.Cut(kv, "=")
+		if key == "" {
+			// Windows keeps per-drive directories as "=C:=C:\dir"
+			continue
+		}
+		Setenv(key, value).Must()
+	}
+	return result.Void(nil)
+}
+
+// Sets the variables, calls f and restores the environment.
+// It is meant for tests: the environment is shared by the whole process.
+func WithEnv(vars map[string]string, f func()) (res result.ResultVoid) {
+	defer result.Catch(&res)
+	snapshot := EnvSnapshot()
+	defer func() {
+		snapshot.Restore().Must()
+	}()
+	for key, value := range vars {
+		Setenv(key, value).Must()
+	}
+	f()
+	return result.Void(nil)
+}
@@ -0,0 +1,70 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package os_r_test
+
+import (
+	"net/netip"
+	"net/url"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/pakuula/go-rusty/result/os_r"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupEnvAs(t *testing.T) {
+	os_r.WithEnv(map[string]string{
+		"RUSTY_PORT":    "8080",
+		"RUSTY_DEBUG":   "true",
+		"RUSTY_TIMEOUT": "5s",
+		"RUSTY_RATIO":   "0.5",
+		"RUSTY_URL":     "https://example.com/path",
+		"RUSTY_ADDR":    "10.0.0.1",
+		"RUSTY_BAD":     "eighty",
+	}, func() {
+		assert.Equal(t, 8080, os_r.LookupEnvAs[int]("RUSTY_PORT").Unwrap().Unwrap())
+		assert.Equal(t, true, os_r.LookupEnvAs[bool]("RUSTY_DEBUG").Unwrap().Unwrap())
+		assert.Equal(t, 5*time.Second, os_r.LookupEnvAs[time.Duration]("RUSTY_TIMEOUT").Unwrap().Unwrap())
+		assert.Equal(t, 0.5, os_r.LookupEnvAs[float64]("RUSTY_RATIO").Unwrap().Unwrap())
+		assert.Equal(t, "example.com", os_r.LookupEnvAs[url.URL]("RUSTY_URL").Unwrap().Unwrap().Host)
+		assert.Equal(t, "/path", os_r.LookupEnvAs[*url.URL]("RUSTY_URL").Unwrap().Unwrap().Path)
+		assert.Equal(t, netip.MustParseAddr("10.0.0.1"), os_r.LookupEnvAs[netip.Addr]("RUSTY_ADDR").Unwrap().Unwrap())
+		assert.True(t, os_r.LookupEnvAs[int]("RUSTY_MISSING").IsNone())
+
+		bad := os_r.LookupEnvAs[int]("RUSTY_BAD").Unwrap()
+		assert.ErrorIs(t, bad.Err(), strconv.ErrSyntax)
+		var envErr *os_r.EnvError
+		require.ErrorAs(t, bad.Err(), &envErr)
+		assert.Equal(t, "RUSTY_BAD", envErr.Key)
+		assert.Equal(t, "eighty", envErr.Value)
+
+		assert.Equal(t, 8080, os_r.RequireEnvAs[int]("RUSTY_PORT").Unwrap())
+		assert.ErrorIs(t, os_r.RequireEnvAs[int]("RUSTY_MISSING").Err(), os_r.ErrEnvNotSet)
+	}).Must()
+}
+
+func TestRequireEnv(t *testing.T) {
+	os_r.WithEnv(map[string]string{"RUSTY_NAME": "value"}, func() {
+		assert.Equal(t, "value", os_r.RequireEnv("RUSTY_NAME").Unwrap())
+		err := os_r.RequireEnv("RUSTY_MISSING").Err()
+		assert.ErrorIs(t, err, os_r.ErrEnvNotSet)
+		assert.Equal(t, "RUSTY_MISSING: environment variable is not set", err.Error())
+	}).Must()
+}
+
+func TestWithEnv(t *testing.T) {
+	before := os.Environ()
+	res := os_r.WithEnv(map[string]string{"RUSTY_TEMP": "1"}, func() {
+		assert.Equal(t, "1", os.Getenv("RUSTY_TEMP"))
+		os.Setenv("RUSTY_OTHER", "2")
+	})
+	assert.True(t, res.IsValue())
+	assert.True(t, os_r.LookupEnv("RUSTY_TEMP").IsNone())
+	assert.True(t, os_r.LookupEnv("RUSTY_OTHER").IsNone())
+	assert.ElementsMatch(t, before, os.Environ())
+}
@@ -0,0 +1,42 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package os_r
+
+import (
+	"os"
+
+	"github.com/pakuula/go-rusty/result"
+	"github.com/pakuula/go-rusty/result/io_r"
+)
+
+// The wrapper of *os.File is generated by cmd/rusty-wrap.
+// Seek is renamed because go vet reserves it for the io.Seeker signature.
+//
+//	f := os_r.OpenWrapped(name).Must()
+
+//go:generate go run -C ../../cmd ./rusty-wrap -pkg os -name $GOPACKAGE -types File -allow File.* -deny File.ReadFrom,File.WriteTo -rename File.Seek=SeekFrom -o ../result/os_r/file_gen.go
+
+var _ io_r.Reader = (*File)(nil)
+var _ io_r.Writer = (*File)(nil)
+
+// Opens the file for reading, see os.Open
+func OpenWrapped(name string) result.Result[*File] {
+	return result.Apply(Open(name), WrapFile)
+}
+
+// Creates or truncates the file, see os.Create
+func CreateWrapped(name string) result.Result[*File] {
+	return result.Apply(Create(name), WrapFile)
+}
+
+// Creates a new temporary file, see os.CreateTemp
+func CreateTempWrapped(dir string, pattern string) result.Result[*File] {
+	return result.Apply(CreateTemp(dir, pattern), WrapFile)
+}
+
+// Opens the file with the flags and the permissions, see os.OpenFile
+func OpenFileWrapped(name string, flag int, perm os.FileMode) result.Result[*File] {
+	return result.Apply(OpenFile(name, flag, perm), WrapFile)
+}
@@ -0,0 +1,78 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package os_r_test
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pakuula/go-rusty/result"
+	"github.com/pakuula/go-rusty/result/os_r"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeAndRead(dir string) (res result.Result[string]) {
+	defer result.Catch(&res)
+	name := filepath.Join(dir, "file.txt")
+
+	f := os_r.CreateWrapped(name).Must()
+	f.WriteString("hello, ").Must()
+	f.Write([]byte("world")).Must()
+	f.Sync().Must()
+	f.Truncate(int64(len("hello"))).Must()
+	f.Chmod(0o600).Must()
+	f.Close().Must()
+
+	f = os_r.OpenWrapped(name).Must()
+	defer f.Close()
+	f.SeekFrom(1, io.SeekStart).Must()
+	buf := make([]byte, 16)
+	n := f.Read(buf).Must()
+	return result.Val(string(buf[:n]))
+}
+
+func TestFile(t *testing.T) {
+	dir := t.TempDir()
+	assert.Equal(t, "ello", writeAndRead(dir).Unwrap())
+
+	f := os_r.OpenWrapped(dir).Unwrap()
+	defer f.Close()
+	assert.True(t, f.Stat().Unwrap().IsDir())
+	entries := f.ReadDir(-1).Unwrap()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "file.txt", entries[0].Name())
+	assert.Equal(t, dir, f.Unwrap().Name())
+
+	file := os_r.OpenWrapped(filepath.Join(dir, "file.txt")).Unwrap()
+	t.Cleanup(func() { file.Close().Unwrap() })
+	info := file.Stat().Unwrap()
+	assert.Equal(t, fs.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestConstructors(t *testing.T) {
+	dir := t.TempDir()
+	f := os_r.CreateTempWrapped(dir, "*.txt").Unwrap()
+	assert.Equal(t, 2, f.WriteString("hi").Unwrap())
+	f.Close().Unwrap()
+
+	f = os_r.OpenFileWrapped(f.Unwrap().Name(), os.O_APPEND|os.O_WRONLY, 0).Unwrap()
+	assert.Equal(t, 1, f.WriteString("!").Unwrap())
+	f.Close().Unwrap()
+	assert.Equal(t, "hi!", string(os_r.ReadFile(f.Unwrap().Name()).Unwrap()))
+
+	assert.ErrorIs(t, os_r.OpenWrapped(filepath.Join(dir, "missing")).Err(), fs.ErrNotExist)
+	assert.ErrorIs(t, os_r.CreateWrapped(filepath.Join(dir, "missing", "x")).Err(), fs.ErrNotExist)
+}
+
+func TestFileErrors(t *testing.T) {
+	f := result.Apply(os_r.Open(t.TempDir()), os_r.WrapFile).Unwrap()
+	assert.True(t, f.Read(make([]byte, 1)).IsError())
+	assert.True(t, f.WriteString("x").IsError())
+	f.Close().Unwrap()
+	assert.ErrorIs(t, f.Close().Err(), fs.ErrClosed)
+}
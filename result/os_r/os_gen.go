@@ -0,0 +1,229 @@
+// Code generated by rusty-wrap -pkg os -name os_r -deny Clearenv,Exit,Getegid,Getenv,Geteuid,Getgid,Getpagesize,Getpid,Getppid,Getuid,IsExist,IsNotExist,IsPathSeparator,IsPermission,IsTimeout,NewFile,NewSyscallError,CopyFS,OpenInRoot,OpenRoot -o ../result/os_r/os_gen.go; DO NOT EDIT.
+
+package os_r
+
+import (
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/pakuula/go-rusty/option"
+	"github.com/pakuula/go-rusty/result"
+	"github.com/pakuula/go-rusty/tuple"
+)
+
+// Chdir wraps os.Chdir.
+func Chdir(dir string) result.ResultVoid {
+	return result.Void(os.Chdir(dir))
+}
+
+// Chmod wraps os.Chmod.
+func Chmod(name string, mode os.FileMode) result.ResultVoid {
+	return result.Void(os.Chmod(name, mode))
+}
+
+// Chown wraps os.Chown.
+func Chown(name string, uid int, gid int) result.ResultVoid {
+	return result.Void(os.Chown(name, uid, gid))
+}
+
+// Chtimes wraps os.Chtimes.
+func Chtimes(name string, atime time.Time, mtime time.Time) result.ResultVoid {
+	return result.Void(os.Chtimes(name, atime, mtime))
+}
+
+// Create wraps os.Create.
+func Create(name string) result.Result[*os.File] {
+	return result.Wrap(os.Create(name))
+}
+
+// CreateTemp wraps os.CreateTemp.
+func CreateTemp(dir string, pattern string) result.Result[*os.File] {
+	return result.Wrap(os.CreateTemp(dir, pattern))
+}
+
+// DirFS wraps os.DirFS.
+func DirFS(dir string) fs.FS {
+	return os.DirFS(dir)
+}
+
+// Environ wraps os.Environ.
+func Environ() []string {
+	return os.Environ()
+}
+
+// Executable wraps os.Executable.
+func Executable() result.Result[string] {
+	return result.Wrap(os.Executable())
+}
+
+// Expand wraps os.Expand.
+func Expand(s string, mapping func(string) string) string {
+	return os.Expand(s, mapping)
+}
+
+// ExpandEnv wraps os.ExpandEnv.
+func ExpandEnv(s string) string {
+	return os.ExpandEnv(s)
+}
+
+// FindProcess wraps os.FindProcess.
+func FindProcess(pid int) result.Result[*os.Process] {
+	return result.Wrap(os.FindProcess(pid))
+}
+
+// Getgroups wraps os.Getgroups.
+func Getgroups() result.Result[[]int] {
+	return result.Wrap(os.Getgroups())
+}
+
+// Getwd wraps os.Getwd.
+func Getwd() result.Result[string] {
+	return result.Wrap(os.Getwd())
+}
+
+// Hostname wraps os.Hostname.
+func Hostname() result.Result[string] {
+	return result.Wrap(os.Hostname())
+}
+
+// Lchown wraps os.Lchown.
+func Lchown(name string, uid int, gid int) result.ResultVoid {
+	return result.Void(os.Lchown(name, uid, gid))
+}
+
+// Link wraps os.Link.
+func Link(oldname string, newname string) result.ResultVoid {
+	return result.Void(os.Link(oldname, newname))
+}
+
+// LookupEnv wraps os.LookupEnv.
+func LookupEnv(key string) option.Option[string] {
+	return option.WrapOk(os.LookupEnv(key))
+}
+
+// Lstat wraps os.Lstat.
+func Lstat(name string) result.Result[os.FileInfo] {
+	return result.Wrap(os.Lstat(name))
+}
+
+// Mkdir wraps os.Mkdir.
+func Mkdir(name string, perm os.FileMode) result.ResultVoid {
+	return result.Void(os.Mkdir(name, perm))
+}
+
+// MkdirAll wraps os.MkdirAll.
+func MkdirAll(path string, perm os.FileMode) result.ResultVoid {
+	return result.Void(os.MkdirAll(path, perm))
+}
+
+// MkdirTemp wraps os.MkdirTemp.
+func MkdirTemp(dir string, pattern string) result.Result[string] {
+	return result.Wrap(os.MkdirTemp(dir, pattern))
+}
+
+// Open wraps os.Open.
+func Open(name string) result.Result[*os.File] {
+	return result.Wrap(os.Open(name))
+}
+
+// OpenFile wraps os.OpenFile.
+func OpenFile(name string, flag int, perm os.FileMode) result.Result[*os.File] {
+	return result.Wrap(os.OpenFile(name, flag, perm))
+}
+
+// Pipe wraps os.Pipe.
+func Pipe() result.Result[tuple.Pair[*os.File, *os.File]] {
+	r, w, err := os.Pipe()
+	return result.Wrap(tuple.NewPair(r, w), err)
+}
+
+// ReadDir wraps os.ReadDir.
+func ReadDir(name string) result.Result[[]os.DirEntry] {
+	return result.Wrap(os.ReadDir(name))
+}
+
+// ReadFile wraps os.ReadFile.
+func ReadFile(name string) result.Result[[]byte] {
+	return result.Wrap(os.ReadFile(name))
+}
+
+// Readlink wraps os.Readlink.
+func Readlink(name string) result.Result[string] {
+	return result.Wrap(os.Readlink(name))
+}
+
+// Remove wraps os.Remove.
+func Remove(name string) result.ResultVoid {
+	return result.Void(os.Remove(name))
+}
+
+// RemoveAll wraps os.RemoveAll.
+func RemoveAll(path string) result.ResultVoid {
+	return result.Void(os.RemoveAll(path))
+}
+
+// Rename wraps os.Rename.
+func Rename(oldpath string, newpath string) result.ResultVoid {
+	return result.Void(os.Rename(oldpath, newpath))
+}
+
+// SameFile wraps os.SameFile.
+func SameFile(fi1 os.FileInfo, fi2 os.FileInfo) bool {
+	return os.SameFile(fi1, fi2)
+}
+
+// Setenv wraps os.Setenv.
+func Setenv(key string, value string) result.ResultVoid {
+	return result.Void(os.Setenv(key, value))
+}
+
+// StartProcess wraps os.StartProcess.
+func StartProcess(name string, argv []string, attr *os.ProcAttr) result.Result[*os.Process] {
+	return result.Wrap(os.StartProcess(name, argv, attr))
+}
+
+// Stat wraps os.Stat.
+func Stat(name string) result.Result[os.FileInfo] {
+	return result.Wrap(os.Stat(name))
+}
+
+// Symlink wraps os.Symlink.
+func Symlink(oldname string, newname string) result.ResultVoid {
+	return result.Void(os.Symlink(oldname, newname))
+}
+
+// TempDir wraps os.TempDir.
+func TempDir() string {
+	return os.TempDir()
+}
+
+// Truncate wraps os.Truncate.
+func Truncate(name string, size int64) result.ResultVoid {
+	return result.Void(os.Truncate(name, size))
+}
+
+// Unsetenv wraps os.Unsetenv.
+func Unsetenv(key string) result.ResultVoid {
+	return result.Void(os.Unsetenv(key))
+}
+
+// UserCacheDir wraps os.UserCacheDir.
+func UserCacheDir() result.Result[string] {
+	return result.Wrap(os.UserCacheDir())
+}
+
+// UserConfigDir wraps os.UserConfigDir.
+func UserConfigDir() result.Result[string] {
+	return result.Wrap(os.UserConfigDir())
+}
+
+// UserHomeDir wraps os.UserHomeDir.
+func UserHomeDir() result.Result[string] {
+	return result.Wrap(os.UserHomeDir())
+}
+
+// WriteFile wraps os.WriteFile.
+func WriteFile(name string, data []byte, perm os.FileMode) result.ResultVoid {
+	return result.Void(os.WriteFile(name, data, perm))
+}
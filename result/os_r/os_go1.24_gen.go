@@ -0,0 +1,21 @@
+// Code generated by rusty-wrap -pkg os -name os_r -allow OpenInRoot,OpenRoot -tags go1.24 -o ../result/os_r/os_go1.24_gen.go; DO NOT EDIT.
+
+//go:build go1.24
+
+package os_r
+
+import (
+	"os"
+
+	"github.com/pakuula/go-rusty/result"
+)
+
+// OpenInRoot wraps os.OpenInRoot.
+func OpenInRoot(dir string, name string) result.Result[*os.File] {
+	return result.Wrap(os.OpenInRoot(dir, name))
+}
+
+// OpenRoot wraps os.OpenRoot.
+func OpenRoot(name string) result.Result[*os.Root] {
+	return result.Wrap(os.OpenRoot(name))
+}
@@ -0,0 +1,138 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package os_r_test
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pakuula/go-rusty/result/os_r"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Functions of os that can't fail and are not worth wrapping
+var notWrapped = map[string]bool{
+	"Clearenv":        true,
+	"Exit":            true,
+	"Getegid":         true,
+	"Getenv":          true,
+	"Geteuid":         true,
+	"Getgid":          true,
+	"Getpagesize":     true,
+	"Getpid":          true,
+	"Getppid":         true,
+	"Getuid":          true,
+	"IsExist":         true,
+	"IsNotExist":      true,
+	"IsPathSeparator": true,
+	"IsPermission":    true,
+	"IsTimeout":       true,
+	"NewFile":         true,
+	"NewSyscallError": true,
+}
+
+// Functions of os/user and their wrappers
+var userWrappers = map[string]string{
+	"Current":       "CurrentUser",
+	"Lookup":        "LookupUser",
+	"LookupId":      "LookupUserId",
+	"LookupGroup":   "LookupGroup",
+	"LookupGroupId": "LookupGroupId",
+}
+
+func exportedFuncs(t *testing.T, path string) []string {
+	pkg, err := importer.ForCompiler(token.NewFileSet(), "source", nil).Import(path)
+	require.NoError(t, err)
+	var names []string
+	for _, name := range pkg.Scope().Names() {
+		if _, ok := pkg.Scope().Lookup(name).(*types.Func); ok && token.IsExported(name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func wrappers(t *testing.T) map[string]bool {
+	pkgs, err := parser.ParseDir(token.NewFileSet(), ".", func(fi fs.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	require.NoError(t, err)
+	names := map[string]bool{}
+	for _, file := range pkgs["os_r"].Files {
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil {
+				names[fn.Name.Name] = true
+			}
+		}
+	}
+	return names
+}
+
+func TestCoverage(t *testing.T) {
+	wrapped := wrappers(t)
+	for _, name := range exportedFuncs(t, "os") {
+		if !notWrapped[name] {
+			assert.True(t, wrapped[name], "os.%s has no wrapper", name)
+		}
+	}
+	for _, name := range exportedFuncs(t, "os/user") {
+		assert.True(t, wrapped[userWrappers[name]], "user.%s has no wrapper", name)
+	}
+}
+
+func TestStat(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "file.txt")
+	assert.True(t, os_r.StatOpt(name).Unwrap().IsNone())
+	assert.ErrorIs(t, os_r.Stat(name).Err(), fs.ErrNotExist)
+
+	os_r.WriteFile(name, []byte("data"), 0o600).Unwrap()
+	info := os_r.StatOpt(name).Unwrap().Unwrap()
+	assert.Equal(t, int64(4), info.Size())
+	assert.True(t, os_r.SameFile(info, os_r.Lstat(name).Unwrap()))
+
+	link := filepath.Join(dir, "link")
+	os_r.Symlink(name, link).Unwrap()
+	assert.Equal(t, fs.ModeSymlink, os_r.LstatOpt(link).Unwrap().Unwrap().Mode().Type())
+
+	entries := os_r.ReadDir(dir).Unwrap()
+	assert.Len(t, entries, 2)
+	assert.True(t, os_r.ReadDir(name).IsError())
+}
+
+func TestPipe(t *testing.T) {
+	r, w := os_r.Pipe().Unwrap().Unwrap()
+	defer r.Close()
+	os_r.WrapFile(w).WriteString("ping").Unwrap()
+	w.Close()
+	buf := make([]byte, 8)
+	n := os_r.WrapFile(r).Read(buf).Unwrap()
+	assert.Equal(t, "ping", string(buf[:n]))
+}
+
+func TestCreateTemp(t *testing.T) {
+	f := os_r.CreateTemp(t.TempDir(), "rusty-*.txt").Unwrap()
+	defer f.Close()
+	assert.True(t, strings.HasPrefix(filepath.Base(f.Name()), "rusty-"))
+	assert.Equal(t, os.TempDir(), os_r.TempDir())
+}
+
+func TestUser(t *testing.T) {
+	current := os_r.CurrentUser()
+	if current.IsError() {
+		t.Skip("current user is not available:", current.Err())
+	}
+	assert.Equal(t, current.Unwrap().Uid, os_r.LookupUserId(current.Unwrap().Uid).Unwrap().Uid)
+	assert.True(t, os_r.LookupUser("no-such-user-rusty").IsError())
+}
@@ -0,0 +1,34 @@
+// Code generated by rusty-wrap -pkg os/user -name os_r -allow Current,Lookup,LookupId,LookupGroup,LookupGroupId -rename Current=CurrentUser,Lookup=LookupUser,LookupId=LookupUserId -o ../result/os_r/user_gen.go; DO NOT EDIT.
+
+package os_r
+
+import (
+	"os/user"
+
+	"github.com/pakuula/go-rusty/result"
+)
+
+// CurrentUser wraps user.Current.
+func CurrentUser() result.Result[*user.User] {
+	return result.Wrap(user.Current())
+}
+
+// LookupUser wraps user.Lookup.
+func LookupUser(username string) result.Result[*user.User] {
+	return result.Wrap(user.Lookup(username))
+}
+
+// LookupGroup wraps user.LookupGroup.
+func LookupGroup(name string) result.Result[*user.Group] {
+	return result.Wrap(user.LookupGroup(name))
+}
+
+// LookupGroupId wraps user.LookupGroupId.
+func LookupGroupId(gid string) result.Result[*user.Group] {
+	return result.Wrap(user.LookupGroupId(gid))
+}
+
+// LookupUserId wraps user.LookupId.
+func LookupUserId(uid string) result.Result[*user.User] {
+	return result.Wrap(user.LookupId(uid))
+}
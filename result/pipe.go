@@ -0,0 +1,168 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package result
+
+// An error returned by a named stage of a pipeline
+type StageError struct {
+	Stage string
+	Err   error
+}
+
+func (self *StageError) Error() string {
+	return self.Stage + ": " + self.Err.Error()
+}
+
+func (self *StageError) Unwrap() error {
+	return self.Err
+}
+
+// Names the stage of a pipeline: its error is wrapped into *StageError
+func Named[T any, U any](name string, f func(T) Result[U]) func(T) Result[U] {
+	return func(t T) Result[U] {
+		res := f(t)
+		if res.IsError() {
+			return Err[U](&StageError{Stage: name, Err: res.err})
+		}
+		return res
+	}
+}
+
+// Passes the value through the stages.
+// Stops at the first stage that returns an error.
+func Pipe[T any](res Result[T], stages ...func(T) Result[T]) Result[T] {
+	for _, f := range stages {
+		if res.IsError() {
+			return res
+		}
+		res = f(res.value)
+	}
+	return res
+}
+
+// Combines the stages into a single function
+func Compose[T any](stages ...func(T) Result[T]) func(T) Result[T] {
+	return func(t T) Result[T] {
+		return Pipe(Val(t), stages...)
+	}
+}
+
+// Combines two functions with different types into a single function
+func Compose2[A any, B any, C any](f1 func(A) Result[B], f2 func(B) Result[C]) func(A) Result[C] {
+	return func(a A) Result[C] {
+		return ApplyResult(f1(a), f2)
+	}
+}
+
+// Passes the result through two stages with different types
+func Pipe2[A any, B any, C any](
+	res Result[A],
+	f1 func(A) Result[B],
+	f2 func(B) Result[C],
+) Result[C] {
+	r1 := ApplyResult(res, f1)
+	return ApplyResult(r1, f2)
+}
+
+// Passes the result through three stages with different types
+func Pipe3[A any, B any, C any, D any](
+	res Result[A],
+	f1 func(A) Result[B],
+	f2 func(B) Result[C],
+	f3 func(C) Result[D],
+) Result[D] {
+	r1 := ApplyResult(res, f1)
+	r2 := ApplyResult(r1, f2)
+	return ApplyResult(r2, f3)
+}
+
+// Passes the result through four stages with different types
+func Pipe4[A any, B any, C any, D any, E any](
+	res Result[A],
+	f1 func(A) Result[B],
+	f2 func(B) Result[C],
+	f3 func(C) Result[D],
+	f4 func(D) Result[E],
+) Result[E] {
+	r1 := ApplyResult(res, f1)
+	r2 := ApplyResult(r1, f2)
+	r3 := ApplyResult(r2, f3)
+	return ApplyResult(r3, f4)
+}
+
+// Passes the result through five stages with different types
+func Pipe5[A any, B any, C any, D any, E any, F any](
+	res Result[A],
+	f1 func(A) Result[B],
+	f2 func(B) Result[C],
+	f3 func(C) Result[D],
+	f4 func(D) Result[E],
+	f5 func(E) Result[F],
+) Result[F] {
+	r1 := ApplyResult(res, f1)
+	r2 := ApplyResult(r1, f2)
+	r3 := ApplyResult(r2, f3)
+	r4 := ApplyResult(r3, f4)
+	return ApplyResult(r4, f5)
+}
+
+// Passes the result through six stages with different types
+func Pipe6[A any, B any, C any, D any, E any, F any, G any](
+	res Result[A],
+	f1 func(A) Result[B],
+	f2 func(B) Result[C],
+	f3 func(C) Result[D],
+	f4 func(D) Result[E],
+	f5 func(E) Result[F],
+	f6 func(F) Result[G],
+) Result[G] {
+	r1 := ApplyResult(res, f1)
+	r2 := ApplyResult(r1, f2)
+	r3 := ApplyResult(r2, f3)
+	r4 := ApplyResult(r3, f4)
+	r5 := ApplyResult(r4, f5)
+	return ApplyResult(r5, f6)
+}
+
+// Passes the result through seven stages with different types
+func Pipe7[A any, B any, C any, D any, E any, F any, G any, H any](
+	res Result[A],
+	f1 func(A) Result[B],
+	f2 func(B) Result[C],
+	f3 func(C) Result[D],
+	f4 func(D) Result[E],
+	f5 func(E) Result[F],
+	f6 func(F) Result[G],
+	f7 func(G) Result[H],
+) Result[H] {
+	r1 := ApplyResult(res, f1)
+	r2 := ApplyResult(r1, f2)
+	r3 := ApplyResult(r2, f3)
+	r4 := ApplyResult(r3, f4)
+	r5 := ApplyResult(r4, f5)
+	r6 := ApplyResult(r5, f6)
+	return ApplyResult(r6, f7)
+}
+
+// Passes the result through eight stages with different types
+func Pipe8[A any, B any, C any, D any, E any, F any, G any, H any, I any](
+	res Result[A],
+	f1 func(A) Result[B],
+	f2 func(B) Result[C],
+	f3 func(C) Result[D],
+	f4 func(D) Result[E],
+	f5 func(E) Result[F],
+	f6 func(F) Result[G],
+	f7 func(G) Result[H],
+	f8 func(H) Result[I],
+) Result[I] {
+	r1 := ApplyResult(res, f1)
+	r2 := ApplyResult(r1, f2)
+	r3 := ApplyResult(r2, f3)
+	r4 := ApplyResult(r3, f4)
+	r5 := ApplyResult(r4, f5)
+	r6 := ApplyResult(r5, f6)
+	r7 := ApplyResult(r6, f7)
+	return ApplyResult(r7, f8)
+}
@@ -0,0 +1,57 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package result_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/pakuula/go-rusty/result"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errNegative = errors.New("negative")
+
+func inc(v int) TR { return ValTR(v + 1) }
+
+func positive(v int) TR {
+	if v < 0 {
+		return ErrTR(errNegative)
+	}
+	return ValTR(v)
+}
+
+func TestPipe(t *testing.T) {
+	assert.Equal(t, 3, result.Pipe(ValTR(1), inc, positive, inc).Unwrap())
+
+	calls := 0
+	counting := func(v int) TR { calls++; return ValTR(v) }
+	res := result.Pipe(ValTR(-5), positive, counting)
+	assert.ErrorIs(t, res.Err(), errNegative)
+	assert.Equal(t, 0, calls)
+
+	res = result.Pipe(ValTR(-5), result.Named("check", positive))
+	var stageErr *result.StageError
+	require.ErrorAs(t, res.Err(), &stageErr)
+	assert.Equal(t, "check", stageErr.Stage)
+	assert.Equal(t, "check: negative", res.Err().Error())
+
+	assert.Equal(t, 2, result.Compose(inc, inc)(0).Unwrap())
+}
+
+func TestPipeN(t *testing.T) {
+	parse := func(s string) TR { return result.Wrap(strconv.Atoi(s)) }
+	format := func(v int) result.Result[string] { return result.Val(strconv.Itoa(v)) }
+
+	assert.Equal(t, "42", result.Pipe3(result.Val("41"), parse, inc, format).Unwrap())
+
+	res := result.Pipe3(result.Val("x"), result.Named("parse", parse), inc, format)
+	assert.ErrorIs(t, res.Err(), strconv.ErrSyntax)
+	assert.Contains(t, res.Err().Error(), "parse: ")
+
+	assert.Equal(t, "2", result.Compose2(parse, format)("2").Unwrap())
+}
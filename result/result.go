@@ -10,6 +10,8 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+
+	"github.com/pakuula/go-rusty/tuple"
 )
 
 // A value or an error
@@ -334,6 +336,26 @@ func ApplyResult[T any, U any](from Result[T], f func(T) Result[U]) Result[U] {
 	return f(from.value)
 }
 
+// Combines two results into a result of a pair.
+// Returns the first error if any of the results is an error.
+func Zip[A any, B any](a Result[A], b Result[B]) Result[tuple.Pair[A, B]] {
+	if a.IsError() {
+		return Err[tuple.Pair[A, B]](a.err)
+	}
+	if b.IsError() {
+		return Err[tuple.Pair[A, B]](b.err)
+	}
+	return Val(tuple.NewPair(a.value, b.value))
+}
+
+// Splits a result of a pair into a pair of results
+func Unzip[A any, B any](res Result[tuple.Pair[A, B]]) (Result[A], Result[B]) {
+	if res.IsError() {
+		return Err[A](res.err), Err[B](res.err)
+	}
+	return Val(res.value.First), Val(res.value.Second)
+}
+
 // Utility functions
 
 // Unmarshal JSON data into a value of the type T or error
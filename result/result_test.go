@@ -7,6 +7,7 @@ package result_test
 import (
 	"errors"
 	"fmt"
+	"io/fs"
 	"math/rand"
 	"testing"
 
@@ -58,7 +59,7 @@ func BenchmarkCatch(b *testing.B) {
 		return result.Val(slice)
 	}
 	for i := 0; i < b.N; i++ {
-		sample()
+		sample() //rusty:ignore the benchmark measures the call
 	}
 }
 
@@ -75,3 +76,38 @@ func BenchmarkReturn(b *testing.B) {
 		sample()
 	}
 }
+
+func TestZip(t *testing.T) {
+	zipped := result.Zip(ValTR(1), result.Val("one"))
+	require.True(t, zipped.IsValue())
+	assert.Equal(t, "(1, one)", zipped.String())
+	assert.Equal(t, errTest, result.Zip(ValTR(1), result.Err[string](errTest)).Err())
+
+	a, b := result.Unzip(zipped)
+	assert.Equal(t, 1, a.Unwrap())
+	assert.Equal(t, "one", b.Unwrap())
+}
+
+func TestMatch(t *testing.T) {
+	describe := func(res TR) string {
+		return result.Match(res,
+			func(v int) string { return "value" },
+			func(err error) string { return err.Error() },
+		)
+	}
+	assert.Equal(t, "value", describe(ValTR(1)))
+	assert.Equal(t, "test error", describe(ErrTR(errTest)))
+}
+
+func TestMatchErr(t *testing.T) {
+	classify := func(res TR) string {
+		return result.MatchErr(res, func(int) string { return "value" }).
+			Is(errTest, func(error) string { return "test" }).
+			As(result.CaseAs(func(e *fs.PathError) string { return "path " + e.Path })).
+			Default(func(error) string { return "other" })
+	}
+	assert.Equal(t, "value", classify(ValTR(1)))
+	assert.Equal(t, "test", classify(ErrTR(fmt.Errorf("wrapped: %w", errTest))))
+	assert.Equal(t, "path a.txt", classify(ErrTR(&fs.PathError{Op: "open", Path: "a.txt", Err: fs.ErrNotExist})))
+	assert.Equal(t, "other", classify(ErrTR(errors.New("other"))))
+}
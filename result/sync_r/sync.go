@@ -0,0 +1,233 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package sync_r
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pakuula/go-rusty/option"
+	"github.com/pakuula/go-rusty/result"
+)
+
+// Defines what happens when a panic escapes while the lock is held
+type PoisonPolicy int
+
+const (
+	// The panic is propagated, the lock is released and stays usable
+	IgnorePanics PoisonPolicy = iota
+	// The panic is propagated, the lock is released and marked poisoned
+	PoisonOnPanic
+)
+
+var ErrPoisoned = errors.New("lock is poisoned")
+
+type poison struct {
+	policy PoisonPolicy
+	flag   atomic.Bool
+}
+
+// Access to the value protected by a lock.
+// Call Unlock in the defer statement: defer guard.Unlock()
+type Guard[T any] struct {
+	value  *T
+	unlock func()
+	poison *poison
+}
+
+// Returns the pointer to the protected value.
+// The pointer must not be used after Unlock.
+func (self Guard[T]) Get() *T {
+	return self.value
+}
+
+// Releases the lock.
+//
+// If Unlock is deferred and the function panics, a write guard of
+// a lock with PoisonOnPanic policy marks the lock poisoned.
+func (self Guard[T]) Unlock() {
+	if self.poison != nil && self.poison.policy == PoisonOnPanic {
+		if panicValue := recover(); panicValue != nil {
+			self.poison.flag.Store(true)
+			self.unlock()
+			panic(panicValue)
+		}
+	}
+	self.unlock()
+}
+
+// Mutex
+
+// A mutual exclusion lock that owns the protected value
+type Mutex[T any] struct {
+	mu     sync.Mutex
+	value  T
+	poison poison
+}
+
+func NewMutex[T any](value T, policy PoisonPolicy) *Mutex[T] {
+	m := &Mutex[T]{value: value}
+	m.poison.policy = policy
+	return m
+}
+
+func (self *Mutex[T]) guard() Guard[T] {
+	return Guard[T]{value: &self.value, unlock: self.mu.Unlock, poison: &self.poison}
+}
+
+// Locks the mutex and returns the guard.
+// The lock is acquired even if it is poisoned, check IsPoisoned if it matters.
+func (self *Mutex[T]) Lock() Guard[T] {
+	self.mu.Lock()
+	return self.guard()
+}
+
+// Tries to lock the mutex without blocking. Returns None if the mutex is locked.
+func (self *Mutex[T]) TryLock() option.Option[Guard[T]] {
+	if !self.mu.TryLock() {
+		return option.None[Guard[T]]()
+	}
+	return option.Some(self.guard())
+}
+
+// Calls f with the protected value while holding the lock.
+// Returns ErrPoisoned without calling f if the mutex is poisoned.
+func (self *Mutex[T]) With(f func(*T)) result.ResultVoid {
+	guard := self.Lock()
+	defer guard.Unlock()
+	if self.IsPoisoned() {
+		return result.Void(ErrPoisoned)
+	}
+	f(guard.Get())
+	return result.Void(nil)
+}
+
+// True if a panic escaped while the mutex was held
+func (self *Mutex[T]) IsPoisoned() bool {
+	return self.poison.flag.Load()
+}
+
+// Clears the poisoned state
+func (self *Mutex[T]) ClearPoison() {
+	self.poison.flag.Store(false)
+}
+
+// Calls f with the protected value while holding the lock and returns its result.
+// Returns ErrPoisoned without calling f if the mutex is poisoned.
+func WithR[T any, U any](m *Mutex[T], f func(*T) result.Result[U]) result.Result[U] {
+	guard := m.Lock()
+	defer guard.Unlock()
+	if m.IsPoisoned() {
+		return result.Err[U](ErrPoisoned)
+	}
+	return f(guard.Get())
+}
+
+// RwLock
+
+// A reader/writer lock that owns the protected value
+type RwLock[T any] struct {
+	mu     sync.RWMutex
+	value  T
+	poison poison
+}
+
+func NewRwLock[T any](value T, policy PoisonPolicy) *RwLock[T] {
+	l := &RwLock[T]{value: value}
+	l.poison.policy = policy
+	return l
+}
+
+func (self *RwLock[T]) writeGuard() Guard[T] {
+	return Guard[T]{value: &self.value, unlock: self.mu.Unlock, poison: &self.poison}
+}
+
+func (self *RwLock[T]) readGuard() Guard[T] {
+	return Guard[T]{value: &self.value, unlock: self.mu.RUnlock}
+}
+
+// Locks for writing and returns the guard
+func (self *RwLock[T]) Lock() Guard[T] {
+	self.mu.Lock()
+	return self.writeGuard()
+}
+
+// Locks for reading and returns the guard.
+// The value must not be modified through the read guard.
+func (self *RwLock[T]) RLock() Guard[T] {
+	self.mu.RLock()
+	return self.readGuard()
+}
+
+// Tries to lock for writing without blocking
+func (self *RwLock[T]) TryLock() option.Option[Guard[T]] {
+	if !self.mu.TryLock() {
+		return option.None[Guard[T]]()
+	}
+	return option.Some(self.writeGuard())
+}
+
+// Tries to lock for reading without blocking
+func (self *RwLock[T]) TryRLock() option.Option[Guard[T]] {
+	if !self.mu.TryRLock() {
+		return option.None[Guard[T]]()
+	}
+	return option.Some(self.readGuard())
+}
+
+// Calls f with the protected value while holding the write lock.
+// Returns ErrPoisoned without calling f if the lock is poisoned.
+func (self *RwLock[T]) With(f func(*T)) result.ResultVoid {
+	guard := self.Lock()
+	defer guard.Unlock()
+	if self.IsPoisoned() {
+		return result.Void(ErrPoisoned)
+	}
+	f(guard.Get())
+	return result.Void(nil)
+}
+
+// Calls f with a copy of the protected value while holding the read lock.
+// Returns ErrPoisoned without calling f if the lock is poisoned.
+func (self *RwLock[T]) Read(f func(T)) result.ResultVoid {
+	guard := self.RLock()
+	defer guard.Unlock()
+	if self.IsPoisoned() {
+		return result.Void(ErrPoisoned)
+	}
+	f(*guard.Get())
+	return result.Void(nil)
+}
+
+// True if a panic escaped while the write lock was held
+func (self *RwLock[T]) IsPoisoned() bool {
+	return self.poison.flag.Load()
+}
+
+// Clears the poisoned state
+func (self *RwLock[T]) ClearPoison() {
+	self.poison.flag.Store(false)
+}
+
+// Calls f with the protected value while holding the write lock and returns its result
+func WriteR[T any, U any](l *RwLock[T], f func(*T) result.Result[U]) result.Result[U] {
+	guard := l.Lock()
+	defer guard.Unlock()
+	if l.IsPoisoned() {
+		return result.Err[U](ErrPoisoned)
+	}
+	return f(guard.Get())
+}
+
+// Calls f with a copy of the protected value while holding the read lock and returns its result
+func ReadR[T any, U any](l *RwLock[T], f func(T) result.Result[U]) result.Result[U] {
+	guard := l.RLock()
+	defer guard.Unlock()
+	if l.IsPoisoned() {
+		return result.Err[U](ErrPoisoned)
+	}
+	return f(*guard.Get())
+}
@@ -0,0 +1,99 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package sync_r_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/pakuula/go-rusty/result"
+	"github.com/pakuula/go-rusty/result/sync_r"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errTest = errors.New("test error")
+
+func TestMutex(t *testing.T) {
+	m := sync_r.NewMutex(0, sync_r.IgnorePanics)
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.With(func(v *int) { *v++ }).Unwrap()
+		}()
+	}
+	wg.Wait()
+
+	guard := m.Lock()
+	assert.Equal(t, 100, *guard.Get())
+	assert.True(t, m.TryLock().IsNone())
+	guard.Unlock()
+
+	opt := m.TryLock()
+	require.True(t, opt.IsSome())
+	opt.Unwrap().Unlock()
+
+	doubled := sync_r.WithR(m, func(v *int) result.Result[int] { return result.Val(*v * 2) })
+	assert.Equal(t, 200, doubled.Unwrap())
+}
+
+func TestPoisoning(t *testing.T) {
+	update := func(m *sync_r.Mutex[int]) (err error) {
+		defer result.CatchError(&err)
+		guard := m.Lock()
+		defer guard.Unlock()
+		*guard.Get() = 1
+		result.Err[int](errTest).Must()
+		return nil
+	}
+
+	poisoning := sync_r.NewMutex(0, sync_r.PoisonOnPanic)
+	assert.ErrorIs(t, update(poisoning), errTest)
+	assert.True(t, poisoning.IsPoisoned())
+	assert.ErrorIs(t, poisoning.With(func(*int) {}).Err(), sync_r.ErrPoisoned)
+	poisoning.ClearPoison()
+	assert.True(t, poisoning.With(func(*int) {}).IsValue())
+
+	ignoring := sync_r.NewMutex(0, sync_r.IgnorePanics)
+	assert.ErrorIs(t, update(ignoring), errTest)
+	assert.False(t, ignoring.IsPoisoned())
+	assert.True(t, ignoring.TryLock().IsSome())
+}
+
+func TestRwLock(t *testing.T) {
+	l := sync_r.NewRwLock(map[string]int{}, sync_r.PoisonOnPanic)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			l.With(func(m *map[string]int) { (*m)["a"]++ }).Unwrap()
+		}()
+		go func() {
+			defer wg.Done()
+			l.Read(func(m map[string]int) { _ = m["a"] }).Unwrap()
+		}()
+	}
+	wg.Wait()
+
+	count := sync_r.ReadR(l, func(m map[string]int) result.Result[int] { return result.Val(m["a"]) })
+	assert.Equal(t, 50, count.Unwrap())
+
+	r1 := l.RLock()
+	r2 := l.TryRLock()
+	require.True(t, r2.IsSome())
+	assert.True(t, l.TryLock().IsNone())
+	r2.Unwrap().Unlock()
+	r1.Unlock()
+
+	assert.Panics(t, func() {
+		l.With(func(*map[string]int) { panic("boom") }).Unwrap()
+	})
+	assert.True(t, l.IsPoisoned())
+	assert.ErrorIs(t, sync_r.WriteR(l, func(*map[string]int) result.Result[int] { return result.Val(0) }).Err(), sync_r.ErrPoisoned)
+}
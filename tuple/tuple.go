@@ -0,0 +1,166 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package tuple
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// A pair of values
+type Pair[A any, B any] struct {
+	First  A
+	Second B
+}
+
+// A triple of values
+type Triple[A any, B any, C any] struct {
+	First  A
+	Second B
+	Third  C
+}
+
+// Constructors
+
+func NewPair[A any, B any](first A, second B) Pair[A, B] {
+	return Pair[A, B]{First: first, Second: second}
+}
+
+func NewTriple[A any, B any, C any](first A, second B, third C) Triple[A, B, C] {
+	return Triple[A, B, C]{First: first, Second: second, Third: third}
+}
+
+// String representaion
+
+// Builds a string representation of the pair: "(first, second)"
+func (self Pair[A, B]) String() string {
+	return fmt.Sprintf("(%v, %v)", self.First, self.Second)
+}
+
+// Builds a string representation of the triple: "(first, second, third)"
+func (self Triple[A, B, C]) String() string {
+	return fmt.Sprintf("(%v, %v, %v)", self.First, self.Second, self.Third)
+}
+
+// Extracting the stored values
+
+// Converts to the pair (first, second)
+func (self Pair[A, B]) Unwrap() (A, B) {
+	return self.First, self.Second
+}
+
+// Converts to the triple (first, second, third)
+func (self Triple[A, B, C]) Unwrap() (A, B, C) {
+	return self.First, self.Second, self.Third
+}
+
+// Transform the tuple
+
+// Swaps the elements of the pair
+func (self Pair[A, B]) Swap() Pair[B, A] {
+	return Pair[B, A]{First: self.Second, Second: self.First}
+}
+
+// JSON
+
+// Encodes the pair as a JSON array of two elements
+func (self Pair[A, B]) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]any{self.First, self.Second})
+}
+
+// Decodes the pair from a JSON array of two elements
+func (self *Pair[A, B]) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if len(raw) != 2 {
+		return fmt.Errorf("pair: expected 2 elements, got %d", len(raw))
+	}
+	// The receiver is left intact on error
+	var pair Pair[A, B]
+	if err := json.Unmarshal(raw[0], &pair.First); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw[1], &pair.Second); err != nil {
+		return err
+	}
+	*self = pair
+	return nil
+}
+
+// Encodes the triple as a JSON array of three elements
+func (self Triple[A, B, C]) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]any{self.First, self.Second, self.Third})
+}
+
+// Decodes the triple from a JSON array of three elements
+func (self *Triple[A, B, C]) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if len(raw) != 3 {
+		return fmt.Errorf("triple: expected 3 elements, got %d", len(raw))
+	}
+	// The receiver is left intact on error
+	var triple Triple[A, B, C]
+	if err := json.Unmarshal(raw[0], &triple.First); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw[1], &triple.Second); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw[2], &triple.Third); err != nil {
+		return err
+	}
+	*self = triple
+	return nil
+}
+
+// Slices
+
+// Combines two slices into a slice of pairs.
+// The result is as long as the shorter slice.
+func Zip[A any, B any](as []A, bs []B) []Pair[A, B] {
+	n := min(len(as), len(bs))
+	retval := make([]Pair[A, B], n)
+	for i := 0; i < n; i++ {
+		retval[i] = NewPair(as[i], bs[i])
+	}
+	return retval
+}
+
+// Splits a slice of pairs into two slices
+func Unzip[A any, B any](pairs []Pair[A, B]) ([]A, []B) {
+	as := make([]A, len(pairs))
+	bs := make([]B, len(pairs))
+	for i, p := range pairs {
+		as[i], bs[i] = p.First, p.Second
+	}
+	return as, bs
+}
+
+// Combines three slices into a slice of triples.
+// The result is as long as the shortest slice.
+func Zip3[A any, B any, C any](as []A, bs []B, cs []C) []Triple[A, B, C] {
+	n := min(len(as), len(bs), len(cs))
+	retval := make([]Triple[A, B, C], n)
+	for i := 0; i < n; i++ {
+		retval[i] = NewTriple(as[i], bs[i], cs[i])
+	}
+	return retval
+}
+
+// Splits a slice of triples into three slices
+func Unzip3[A any, B any, C any](triples []Triple[A, B, C]) ([]A, []B, []C) {
+	as := make([]A, len(triples))
+	bs := make([]B, len(triples))
+	cs := make([]C, len(triples))
+	for i, t := range triples {
+		as[i], bs[i], cs[i] = t.First, t.Second, t.Third
+	}
+	return as, bs, cs
+}
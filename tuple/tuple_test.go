@@ -0,0 +1,62 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package tuple_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pakuula/go-rusty/tuple"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPair(t *testing.T) {
+	p := tuple.NewPair(1, "one")
+	a, b := p.Unwrap()
+	assert.Equal(t, 1, a)
+	assert.Equal(t, "one", b)
+	assert.Equal(t, "(1, one)", p.String())
+	assert.Equal(t, tuple.NewPair("one", 1), p.Swap())
+
+	bz, err := json.Marshal(p)
+	require.NoError(t, err)
+	assert.Equal(t, `[1,"one"]`, string(bz))
+
+	var decoded tuple.Pair[int, string]
+	require.NoError(t, json.Unmarshal(bz, &decoded))
+	assert.Equal(t, p, decoded)
+	assert.Error(t, json.Unmarshal([]byte(`[1]`), &decoded))
+	assert.Error(t, json.Unmarshal([]byte(`[2, 3]`), &decoded))
+	assert.Equal(t, p, decoded)
+}
+
+func TestTriple(t *testing.T) {
+	tr := tuple.NewTriple(1, "one", true)
+	assert.Equal(t, "(1, one, true)", tr.String())
+
+	bz, err := json.Marshal(tr)
+	require.NoError(t, err)
+	var decoded tuple.Triple[int, string, bool]
+	require.NoError(t, json.Unmarshal(bz, &decoded))
+	assert.Equal(t, tr, decoded)
+	assert.Error(t, json.Unmarshal([]byte(`[2, "two", "true"]`), &decoded))
+	assert.Equal(t, tr, decoded)
+}
+
+func TestZip(t *testing.T) {
+	pairs := tuple.Zip([]int{1, 2, 3}, []string{"a", "b"})
+	assert.Equal(t, []tuple.Pair[int, string]{{1, "a"}, {2, "b"}}, pairs)
+
+	as, bs := tuple.Unzip(pairs)
+	assert.Equal(t, []int{1, 2}, as)
+	assert.Equal(t, []string{"a", "b"}, bs)
+
+	triples := tuple.Zip3([]int{1}, []string{"a"}, []bool{true, false})
+	xs, ys, zs := tuple.Unzip3(triples)
+	assert.Equal(t, []int{1}, xs)
+	assert.Equal(t, []string{"a"}, ys)
+	assert.Equal(t, []bool{true}, zs)
+}
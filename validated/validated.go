@@ -0,0 +1,265 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package validated
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pakuula/go-rusty/result"
+)
+
+// An error tagged with the path of the field that caused it
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (self FieldError) Error() string {
+	if self.Field == "" {
+		return self.Err.Error()
+	}
+	return self.Field + ": " + self.Err.Error()
+}
+
+func (self FieldError) Unwrap() error {
+	return self.Err
+}
+
+// A list of field errors.
+//
+// Errors implements Unwrap() []error, so errors.Is and errors.As
+// inspect every field error the same way they do for errors.Join.
+type Errors []FieldError
+
+// Joins the messages of the field errors with newlines
+func (self Errors) Error() string {
+	msgs := make([]string, len(self))
+	for i, e := range self {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (self Errors) Unwrap() []error {
+	errs := make([]error, len(self))
+	for i, e := range self {
+		errs[i] = e
+	}
+	return errs
+}
+
+// A value or the list of all the errors found while validating it
+type Validated[T any] struct {
+	value T
+	errs  Errors
+}
+
+// Constructors
+
+func Valid[T any](value T) Validated[T] {
+	return Validated[T]{value: value}
+}
+
+func Invalid[T any](field string, err error) Validated[T] {
+	if err == nil {
+		panic("Not an error")
+	}
+	return Validated[T]{errs: Errors{{Field: field, Err: err}}}
+}
+
+// Builds an invalid object from the list of field errors
+func InvalidErrors[T any](errs Errors) Validated[T] {
+	if len(errs) == 0 {
+		panic("Not an error")
+	}
+	return Validated[T]{errs: errs}
+}
+
+// Converts Result[T] into Validated[T] tagging the error with the field name
+func FromResult[T any](field string, res result.Result[T]) Validated[T] {
+	if res.IsError() {
+		return Invalid[T](field, res.Err())
+	}
+	return Valid(res.Unwrap())
+}
+
+// String representaion
+
+// Builds a string representation of the Validated object.
+// If it is invalid, returns "invalid: " followed by the error messages.
+func (self Validated[T]) String() string {
+	if self.IsInvalid() {
+		return "invalid: " + self.errs.Error()
+	}
+	return fmt.Sprint(self.value)
+}
+
+// Check the Validated
+
+// True if self contains a value
+func (self Validated[T]) IsValid() bool {
+	return len(self.errs) == 0
+}
+
+// True if self contains errors
+func (self Validated[T]) IsInvalid() bool {
+	return len(self.errs) != 0
+}
+
+// Extracting the stored value
+
+var ErrUnwrapInvalid = errors.New("unwrapping invalid value")
+
+// Returns the stored value or panics
+func (self Validated[T]) Unwrap() T {
+	if self.IsInvalid() {
+		panic(fmt.Errorf("%w: %w", ErrUnwrapInvalid, self.errs))
+	}
+	return self.value
+}
+
+// Returns the stored value or the provided default value
+func (self Validated[T]) UnwrapOr(valueIfInvalid T) T {
+	if self.IsInvalid() {
+		return valueIfInvalid
+	}
+	return self.value
+}
+
+// Returns the list of errors, nil for a valid object
+func (self Validated[T]) Errors() Errors {
+	return self.errs
+}
+
+// Converts to Result[T]. The error is of the type Errors.
+func (self Validated[T]) ToResult() result.Result[T] {
+	if self.IsInvalid() {
+		return result.Err[T](self.errs)
+	}
+	return result.Val(self.value)
+}
+
+// Field paths
+
+// Prepends the path to the field of every error: "field" becomes "path.field",
+// the index "[0]" becomes "path[0]"
+func (self Validated[T]) Prefix(path string) Validated[T] {
+	if self.IsValid() || path == "" {
+		return self
+	}
+	errs := make(Errors, len(self.errs))
+	for i, e := range self.errs {
+		errs[i] = e
+		switch {
+		case e.Field == "":
+			errs[i].Field = path
+		case strings.HasPrefix(e.Field, "["):
+			errs[i].Field = path + e.Field
+		default:
+			errs[i].Field = path + "." + e.Field
+		}
+	}
+	return Validated[T]{errs: errs}
+}
+
+// Transform the Validated
+
+// Applies f to the stored value or keeps the errors unchanged
+func Apply[T any, U any](from Validated[T], f func(T) U) Validated[U] {
+	if from.IsInvalid() {
+		return Validated[U]{errs: from.errs}
+	}
+	return Valid(f(from.value))
+}
+
+// Applies f to the stored value or keeps the errors unchanged.
+// Unlike MapN this short-circuits: f is not called for an invalid value.
+func AndThen[T any, U any](from Validated[T], f func(T) Validated[U]) Validated[U] {
+	if from.IsInvalid() {
+		return Validated[U]{errs: from.errs}
+	}
+	return f(from.value)
+}
+
+func collect(errs ...Errors) Errors {
+	var all Errors
+	for _, e := range errs {
+		all = append(all, e...)
+	}
+	return all
+}
+
+// Combines two independent validations.
+// Calls f if both are valid, otherwise accumulates the errors of both.
+func Map2[A any, B any, U any](a Validated[A], b Validated[B], f func(A, B) U) Validated[U] {
+	if errs := collect(a.errs, b.errs); len(errs) != 0 {
+		return Validated[U]{errs: errs}
+	}
+	return Valid(f(a.value, b.value))
+}
+
+// Combines three independent validations
+func Map3[A any, B any, C any, U any](
+	a Validated[A], b Validated[B], c Validated[C],
+	f func(A, B, C) U,
+) Validated[U] {
+	if errs := collect(a.errs, b.errs, c.errs); len(errs) != 0 {
+		return Validated[U]{errs: errs}
+	}
+	return Valid(f(a.value, b.value, c.value))
+}
+
+// Combines four independent validations
+func Map4[A any, B any, C any, D any, U any](
+	a Validated[A], b Validated[B], c Validated[C], d Validated[D],
+	f func(A, B, C, D) U,
+) Validated[U] {
+	if errs := collect(a.errs, b.errs, c.errs, d.errs); len(errs) != 0 {
+		return Validated[U]{errs: errs}
+	}
+	return Valid(f(a.value, b.value, c.value, d.value))
+}
+
+// Combines five independent validations
+func Map5[A any, B any, C any, D any, E any, U any](
+	a Validated[A], b Validated[B], c Validated[C], d Validated[D], e Validated[E],
+	f func(A, B, C, D, E) U,
+) Validated[U] {
+	if errs := collect(a.errs, b.errs, c.errs, d.errs, e.errs); len(errs) != 0 {
+		return Validated[U]{errs: errs}
+	}
+	return Valid(f(a.value, b.value, c.value, d.value, e.value))
+}
+
+// Combines six independent validations
+func Map6[A any, B any, C any, D any, E any, F any, U any](
+	a Validated[A], b Validated[B], c Validated[C], d Validated[D], e Validated[E], g Validated[F],
+	f func(A, B, C, D, E, F) U,
+) Validated[U] {
+	if errs := collect(a.errs, b.errs, c.errs, d.errs, e.errs, g.errs); len(errs) != 0 {
+		return Validated[U]{errs: errs}
+	}
+	return Valid(f(a.value, b.value, c.value, d.value, e.value, g.value))
+}
+
+// Converts a slice of validations into a validation of the slice.
+// The errors of the i-th element are prefixed with "[i]".
+func Sequence[T any](slice []Validated[T]) Validated[[]T] {
+	var errs Errors
+	values := make([]T, len(slice))
+	for i, v := range slice {
+		if v.IsInvalid() {
+			errs = append(errs, v.Prefix(fmt.Sprintf("[%d]", i)).errs...)
+			continue
+		}
+		values[i] = v.value
+	}
+	if len(errs) != 0 {
+		return Validated[[]T]{errs: errs}
+	}
+	return Valid(values)
+}
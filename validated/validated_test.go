@@ -0,0 +1,120 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package validated_test
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"testing"
+
+	"github.com/pakuula/go-rusty/validated"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type User struct {
+	Name  string
+	Age   int
+	Email string
+	Role  string
+}
+
+var reEmail = regexp.MustCompile(`^[^@]+@[^@]+$`)
+
+func validateUser(name string, age int, email string, role string) validated.Validated[User] {
+	return validated.Map4(
+		validated.NonEmpty("name", name),
+		validated.InRange("age", age, 0, 150),
+		validated.Matches("email", email, reEmail),
+		validated.OneOf("role", role, "admin", "user"),
+		func(name string, age int, email string, role string) User {
+			return User{name, age, email, role}
+		},
+	)
+}
+
+func TestValid(t *testing.T) {
+	v := validateUser("bob", 42, "bob@example.com", "user")
+	require.True(t, v.IsValid())
+	assert.Equal(t, User{"bob", 42, "bob@example.com", "user"}, v.Unwrap())
+	assert.True(t, v.ToResult().IsValue())
+}
+
+func TestAccumulate(t *testing.T) {
+	v := validateUser("", 200, "bob", "root")
+	require.True(t, v.IsInvalid())
+	assert.Panics(t, func() { v.Unwrap() })
+
+	errs := v.Errors()
+	require.Len(t, errs, 4)
+	assert.Equal(t, []string{"name", "age", "email", "role"},
+		[]string{errs[0].Field, errs[1].Field, errs[2].Field, errs[3].Field})
+
+	err := v.Prefix("user").ToResult().Err()
+	assert.ErrorIs(t, err, validated.ErrEmpty)
+	assert.ErrorIs(t, err, validated.ErrOutOfRange)
+	assert.ErrorIs(t, err, validated.ErrNoMatch)
+	assert.ErrorIs(t, err, validated.ErrNotAllowed)
+
+	var fe validated.FieldError
+	require.True(t, errors.As(err, &fe))
+	assert.Equal(t, "user.name", fe.Field)
+	assert.Contains(t, err.Error(), "user.role: not allowed")
+	assert.Contains(t, err.Error(), `user.email: "bob" does not match `)
+	assert.NotContains(t, err.Error(), "does not match: ")
+}
+
+func TestSequence(t *testing.T) {
+	v := validated.Sequence([]validated.Validated[string]{
+		validated.NonEmpty("name", "a"),
+		validated.NonEmpty("name", ""),
+	})
+	require.True(t, v.IsInvalid())
+	assert.Equal(t, "[1].name", v.Errors()[0].Field)
+	assert.Equal(t, "users[1].name", v.Prefix("users").Errors()[0].Field)
+
+	nested := validated.Sequence([]validated.Validated[[]string]{v, validated.Sequence([]validated.Validated[string]{
+		validated.Invalid[string]("", validated.ErrEmpty),
+	})})
+	assert.Equal(t, []string{"[0][1].name", "[1][0]"},
+		[]string{nested.Errors()[0].Field, nested.Errors()[1].Field})
+	assert.Equal(t, "a[1][0]", nested.Prefix("a").Errors()[1].Field)
+
+	ok := validated.Sequence([]validated.Validated[int]{validated.Valid(1), validated.Valid(2)})
+	assert.Equal(t, []int{1, 2}, ok.Unwrap())
+}
+
+func TestAll(t *testing.T) {
+	errShort := errors.New("too short")
+	errDigit := errors.New("needs a digit")
+	v := validated.All("password", "abc",
+		func(s string) error {
+			if len(s) < 8 {
+				return errShort
+			}
+			return nil
+		},
+		func(s string) error {
+			if !regexp.MustCompile(`\d`).MatchString(s) {
+				return errDigit
+			}
+			return nil
+		},
+	)
+	require.Len(t, v.Errors(), 2)
+	assert.ErrorIs(t, v.ToResult().Err(), errShort)
+	assert.ErrorIs(t, v.ToResult().Err(), errDigit)
+}
+
+func TestInRange(t *testing.T) {
+	assert.True(t, validated.InRange("ratio", 0.5, 0.0, 1.0).IsValid())
+	assert.True(t, validated.InRange("ratio", 1.0, 0.0, 1.0).IsValid())
+	for _, value := range []float64{-0.1, 1.1, math.NaN()} {
+		v := validated.InRange("ratio", value, 0.0, 1.0)
+		require.False(t, v.IsValid(), value)
+		assert.ErrorIs(t, v.ToResult().Err(), validated.ErrOutOfRange, value)
+	}
+}
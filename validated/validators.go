@@ -0,0 +1,72 @@
+// Copyright 2024 Nikolay Pakulin (@pakuula). All rights reserved.
+// Use of this source code is governed by LGPL-3.0 licence.
+// The text of the licence can be found in the LICENSE.txt file.
+
+package validated
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+var ErrEmpty = errors.New("must not be empty")
+var ErrOutOfRange = errors.New("out of range")
+var ErrNoMatch = errors.New("does not match")
+var ErrNotAllowed = errors.New("not allowed")
+
+// Checks that the value satisfies the condition.
+// Otherwise tags err with the field name.
+func Check[T any](field string, value T, cond func(T) bool, err error) Validated[T] {
+	if !cond(value) {
+		return Invalid[T](field, err)
+	}
+	return Valid(value)
+}
+
+// Runs every check against the value and accumulates all the errors.
+// A check returns nil if the value is acceptable.
+func All[T any](field string, value T, checks ...func(T) error) Validated[T] {
+	var errs Errors
+	for _, check := range checks {
+		if err := check(value); err != nil {
+			errs = append(errs, FieldError{Field: field, Err: err})
+		}
+	}
+	if len(errs) != 0 {
+		return InvalidErrors[T](errs)
+	}
+	return Valid(value)
+}
+
+// Checks that the string is not empty
+func NonEmpty(field string, value string) Validated[string] {
+	return Check(field, value, func(s string) bool { return s != "" }, ErrEmpty)
+}
+
+// Checks that lo <= value <= hi. NaN is not in any range.
+func InRange[T cmp.Ordered](field string, value T, lo T, hi T) Validated[T] {
+	if !(value >= lo && value <= hi) {
+		return Invalid[T](field, fmt.Errorf("%w: %v is not in [%v, %v]", ErrOutOfRange, value, lo, hi))
+	}
+	return Valid(value)
+}
+
+// Checks that the string matches the regular expression
+func Matches(field string, value string, re *regexp.Regexp) Validated[string] {
+	if !re.MatchString(value) {
+		return Invalid[string](field, fmt.Errorf("%q %w %s", value, ErrNoMatch, re))
+	}
+	return Valid(value)
+}
+
+// Checks that the value is one of the allowed values
+func OneOf[T comparable](field string, value T, allowed ...T) Validated[T] {
+	for _, a := range allowed {
+		if value == a {
+			return Valid(value)
+		}
+	}
+	return Invalid[T](field, fmt.Errorf("%w: %v is not one of %v", ErrNotAllowed, value, allowed))
+}